@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/identity"
+	"github.com/vishnusunil243/api_gateway/logging"
+	"github.com/vishnusunil243/api_gateway/requestid"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Event is one privileged operation performed through the gateway.
+type Event struct {
+	Time      time.Time              `json:"time"`
+	ActorID   uint32                 `json:"actorId"`
+	Roles     []string               `json:"roles"`
+	Operation string                 `json:"operation"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Outcome   string                 `json:"outcome"`
+	Error     string                 `json:"error,omitempty"`
+	RequestID string                 `json:"requestId,omitempty"`
+}
+
+// Sink stores audit events.
+type Sink interface {
+	Record(ctx context.Context, e Event) error
+}
+
+// Reader is implemented by sinks that can be searched by the auditLog query.
+type Reader interface {
+	Query(ctx context.Context, f Filter) ([]Event, error)
+}
+
+// MaxLimit is the most events a query returns.
+const MaxLimit = 1000
+
+// Filter selects events; zero fields match everything. Limit is capped at
+// MaxLimit, which zero stands for.
+type Filter struct {
+	ActorID   uint32
+	Operation string
+	Outcome   string
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+func (f Filter) limit() int {
+	if f.Limit <= 0 || f.Limit > MaxLimit {
+		return MaxLimit
+	}
+	return f.Limit
+}
+
+func (f Filter) Match(e Event) bool {
+	if f.ActorID != 0 && e.ActorID != f.ActorID {
+		return false
+	}
+	if f.Operation != "" && e.Operation != f.Operation {
+		return false
+	}
+	if f.Outcome != "" && e.Outcome != f.Outcome {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Auditor records the privileged operations of one gateway into Sink. A
+// nil *Auditor records nothing.
+type Auditor struct {
+	Sink Sink
+}
+
+// Query searches the sink, most recent events first.
+func (a *Auditor) Query(ctx context.Context, f Filter) ([]Event, error) {
+	if a == nil {
+		return nil, fmt.Errorf("the audit log is disabled")
+	}
+	reader, ok := a.Sink.(Reader)
+	if !ok {
+		return nil, fmt.Errorf("the configured audit sink cannot be queried")
+	}
+	return reader.Query(ctx, f)
+}
+
+// Privileged records an audit event for every call of next. It must be
+// wrapped by one of the auth middlewares so the actor is known.
+func (a *Auditor) Privileged(operation string, next graphql.FieldResolveFn) graphql.FieldResolveFn {
+	if a == nil {
+		return next
+	}
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		res, err := next(p)
+		actor, _ := identity.FromContext(p.Context)
+		e := Event{
+			Time:      time.Now().UTC(),
+			ActorID:   actor.UserID,
+			Roles:     actor.Roles,
+			Operation: operation,
+			Arguments: logging.Redact(p.Args),
+			Outcome:   OutcomeSuccess,
+			RequestID: requestid.FromContext(p.Context),
+		}
+		if err != nil {
+			e.Outcome = OutcomeFailure
+			e.Error = err.Error()
+		}
+		if recordErr := a.Sink.Record(p.Context, e); recordErr != nil {
+			logging.FromContext(p.Context).Error("failed to record audit event", "operation", operation, "error", recordErr.Error())
+		}
+		return res, err
+	}
+}
+
+// MultiSink records to every sink and queries the first one that can be read.
+type MultiSink []Sink
+
+func (m MultiSink) Record(ctx context.Context, e Event) error {
+	var firstErr error
+	for _, s := range m {
+		if err := s.Record(ctx, e); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m MultiSink) Query(ctx context.Context, f Filter) ([]Event, error) {
+	for _, s := range m {
+		if reader, ok := s.(Reader); ok {
+			return reader.Query(ctx, f)
+		}
+	}
+	return nil, fmt.Errorf("the configured audit sink cannot be queried")
+}
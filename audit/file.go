@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// FileSink appends events as JSON lines and can search them back.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{path: path, file: f}, nil
+}
+
+func (s *FileSink) Record(_ context.Context, e Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Query reads the file from its end, so that the latest events are found
+// without reading the whole log.
+func (s *FileSink) Query(_ context.Context, f Filter) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	limit := f.limit()
+	var events []Event
+	err = readLinesBackward(file, info.Size(), func(line []byte) bool {
+		var e Event
+		if err := json.Unmarshal(line, &e); err == nil && f.Match(e) {
+			events = append(events, e)
+		}
+		return len(events) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// readLinesBackward calls fn with the non-empty lines of the first size
+// bytes of r, last first, until fn returns false.
+func readLinesBackward(r io.ReaderAt, size int64, fn func(line []byte) bool) error {
+	const chunkSize = 64 * 1024
+	// the start of a line whose end was in the chunk read before
+	var rest []byte
+	for end := size; end > 0; {
+		start := end - chunkSize
+		if start < 0 {
+			start = 0
+		}
+		chunk := make([]byte, end-start, end-start+int64(len(rest)))
+		if n, err := r.ReadAt(chunk, start); n < len(chunk) {
+			return err
+		}
+		chunk = append(chunk, rest...)
+		end = start
+		for {
+			i := bytes.LastIndexByte(chunk, '\n')
+			if i < 0 {
+				break
+			}
+			if line := chunk[i+1:]; len(line) > 0 && !fn(line) {
+				return nil
+			}
+			chunk = chunk[:i]
+		}
+		rest = chunk
+	}
+	if len(rest) > 0 {
+		fn(rest)
+	}
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
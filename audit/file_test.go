@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSinkQuery(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2500; i++ {
+		e := Event{Time: start.Add(time.Duration(i) * time.Second), ActorID: uint32(i%2 + 1), Operation: "AddProduct", Outcome: OutcomeSuccess}
+		if i == 2400 {
+			// longer than a chunk read from the file
+			e.Arguments = map[string]interface{}{"name": strings.Repeat("x", 100*1024)}
+		}
+		if err := sink.Record(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1000 {
+			if _, err := sink.file.WriteString("not json\n\n"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		filter Filter
+		count  int
+		latest int
+	}{
+		{Filter{}, MaxLimit, 2499},
+		{Filter{Limit: 5000}, MaxLimit, 2499},
+		{Filter{Limit: 3}, 3, 2499},
+		{Filter{ActorID: 1, Limit: 2}, 2, 2498},
+		{Filter{Until: start.Add(2400 * time.Second), Limit: 1}, 1, 2400},
+		{Filter{Since: start.Add(2000 * time.Second), Until: start.Add(2009 * time.Second)}, 10, 2009},
+		{Filter{Operation: "AddAdmin"}, 0, 0},
+	} {
+		events, err := sink.Query(ctx, tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != tc.count {
+			t.Errorf("%+v: got %d events, want %d", tc.filter, len(events), tc.count)
+			continue
+		}
+		for i := 1; i < len(events); i++ {
+			if !events[i].Time.Before(events[i-1].Time) {
+				t.Errorf("%+v: events not newest first", tc.filter)
+				break
+			}
+		}
+		if len(events) > 0 && !events[0].Time.Equal(start.Add(time.Duration(tc.latest)*time.Second)) {
+			t.Errorf("%+v: latest event at %s", tc.filter, events[0].Time)
+		}
+	}
+
+	// every event is found when the limit allows it
+	events, err := sink.Query(ctx, Filter{Until: start.Add(999 * time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1000 || !events[999].Time.Equal(start) {
+		t.Errorf("got %d events back to %s", len(events), events[len(events)-1].Time)
+	}
+}
+
+func TestFileSinkQueryEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	if events, err := sink.Query(context.Background(), Filter{}); err != nil || len(events) != 0 {
+		t.Errorf("got %v, %v", events, err)
+	}
+	if err := os.WriteFile(path, []byte(`{"operation":"AddProduct"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// the last line need not end with a newline
+	if events, err := sink.Query(context.Background(), Filter{}); err != nil || len(events) != 1 {
+		t.Errorf("got %v, %v", events, err)
+	}
+}
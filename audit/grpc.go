@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// RecordMethod is the RPC the gRPC sink calls. There is no audit proto in
+// proto-files yet, so events travel as a google.protobuf.Struct until a
+// dedicated audit service exists.
+const RecordMethod = "/audit.AuditService/Record"
+
+// GRPCSink forwards events to a remote audit service.
+type GRPCSink struct {
+	conn grpc.ClientConnInterface
+}
+
+func NewGRPCSink(conn grpc.ClientConnInterface) *GRPCSink {
+	return &GRPCSink{conn: conn}
+}
+
+func (s *GRPCSink) Record(ctx context.Context, e Event) error {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return err
+	}
+	msg, err := structpb.NewStruct(fields)
+	if err != nil {
+		return err
+	}
+	return s.conn.Invoke(ctx, RecordMethod, msg, &emptypb.Empty{})
+}
@@ -1,6 +1,8 @@
 package authorize
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -14,23 +16,37 @@ type Payload struct {
 	jwt.StandardClaims
 }
 
+// TokenLifetime is how long a generated token stays valid.
+var TokenLifetime = 48 * time.Hour
+
 func GenerateJwt(userId uint, isadmin bool, isuadmin bool, secret []byte) (string, error) {
-	expiresat := time.Now().Add(48 * time.Hour)
+	token, _, err := IssueJwt(userId, isadmin, isuadmin, secret)
+	return token, err
+}
+
+// IssueJwt is GenerateJwt that also returns when the token expires.
+func IssueJwt(userId uint, isadmin bool, isuadmin bool, secret []byte) (string, time.Time, error) {
+	expiresat := time.Now().Add(TokenLifetime)
+	tokenId, err := newTokenId()
+	if err != nil {
+		return "", time.Time{}, err
+	}
 
 	jwtclaims := &Payload{
 		UserId:   userId,
 		Isadmin:  isadmin,
 		Isuadmin: isuadmin,
 		StandardClaims: jwt.StandardClaims{
+			Id:        tokenId,
 			ExpiresAt: expiresat.Unix(),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtclaims)
 	tokenstring, err := token.SignedString(secret)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
-	return tokenstring, nil
+	return tokenstring, time.Unix(jwtclaims.ExpiresAt, 0), nil
 }
 func ValidateToken(tokenstring string, secret []byte) (map[string]interface{}, error) {
 	token, err := jwt.ParseWithClaims(tokenstring, &Payload{}, func(t *jwt.Token) (interface{}, error) {
@@ -53,9 +69,18 @@ func ValidateToken(tokenstring string, secret []byte) (map[string]interface{}, e
 		"userId":   claims.UserId,
 		"isadmin":  claims.Isadmin,
 		"isuadmin": claims.Isuadmin,
+		"tokenId":  claims.Id,
 	}
 	if claims.ExpiresAt < time.Now().Unix() {
 		return nil, fmt.Errorf("token expired")
 	}
 	return cred, nil
 }
+
+func newTokenId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,210 @@
+// Package cache keeps the results of public, rarely changing query fields at
+// the gateway. Entries are fresh for the field's TTL and may then be served
+// stale for a while longer while a single background call refreshes them.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/logging"
+	"github.com/vishnusunil243/api_gateway/metrics"
+)
+
+// Entry is a cached field result encoded as JSON.
+type Entry struct {
+	Value    json.RawMessage `json:"value"`
+	StoredAt time.Time       `json:"storedAt"`
+}
+
+// Backend stores entries. Keys start with the field name followed by ":",
+// which is what DeletePrefix is called with on invalidation.
+type Backend interface {
+	Get(ctx context.Context, key string) (Entry, bool, error)
+	Set(ctx context.Context, key string, e Entry) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// Policy sets how long a field's entries are fresh and how long after that
+// they may still be served while being refreshed.
+type Policy struct {
+	TTL   time.Duration
+	Stale time.Duration
+}
+
+func DefaultPolicies() map[string]Policy {
+	return map[string]Policy{
+		"products": {TTL: 30 * time.Second, Stale: 5 * time.Minute},
+		"product":  {TTL: time.Minute, Stale: 5 * time.Minute},
+	}
+}
+
+// RefreshTimeout bounds a background refresh, which outlives the request
+// that triggered it.
+var RefreshTimeout = 10 * time.Second
+
+// Cache holds the entries of one schema. A nil Cache caches nothing and
+// every call goes upstream.
+type Cache struct {
+	Backend  Backend
+	Policies map[string]Policy
+
+	refreshing sync.Map
+
+	// generations counts the invalidations of each field. A result is only
+	// stored when no invalidation happened since its call started, so a call
+	// racing a mutation cannot put the old result back.
+	mu          sync.Mutex
+	generations map[string]uint64
+}
+
+// Field caches the results of next under the field's policy, keyed by its
+// arguments. newResult returns a pointer to the type next resolves to, which
+// is what cached results are decoded into. Only wrap fields whose result is
+// the same for every caller.
+func (c *Cache) Field(field string, newResult func() interface{}, next graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if c == nil || c.Backend == nil {
+			return next(p)
+		}
+		policy, ok := c.Policies[field]
+		if !ok || policy.TTL <= 0 {
+			return next(p)
+		}
+		key, err := key(field, p.Args)
+		if err != nil {
+			return next(p)
+		}
+		e, found, err := c.Backend.Get(p.Context, key)
+		if err != nil {
+			logging.FromContext(p.Context).Error("cache read failed", "field", field, "error", err.Error())
+			found = false
+		}
+		if found {
+			age := time.Since(e.StoredAt)
+			if age < policy.TTL+policy.Stale {
+				res, err := decode(e.Value, newResult)
+				if err == nil {
+					if age < policy.TTL {
+						metrics.CacheRequests.WithLabelValues(field, "hit").Inc()
+					} else {
+						metrics.CacheRequests.WithLabelValues(field, "stale").Inc()
+						c.refresh(p, field, key, next)
+					}
+					return res, nil
+				}
+			}
+		}
+		metrics.CacheRequests.WithLabelValues(field, "miss").Inc()
+		gen := c.generation(field)
+		res, err := next(p)
+		if err != nil {
+			return nil, err
+		}
+		c.store(p.Context, field, key, gen, res)
+		return res, nil
+	}
+}
+
+// refresh reloads a stale entry in the background, at most once per key at
+// a time.
+func (c *Cache) refresh(p graphql.ResolveParams, field, key string, next graphql.FieldResolveFn) {
+	if _, running := c.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(p.Context), RefreshTimeout)
+	// the response has been written by the time the refresh runs, nothing
+	// may set headers on it
+	ctx = context.WithValue(ctx, "httpResponseWriter", nil)
+	p.Context = ctx
+	gen := c.generation(field)
+	go func() {
+		defer cancel()
+		defer c.refreshing.Delete(key)
+		res, err := next(p)
+		if err != nil {
+			logging.FromContext(ctx).Warn("cache refresh failed", "field", field, "error", err.Error())
+			return
+		}
+		c.store(ctx, field, key, gen, res)
+	}()
+}
+
+func (c *Cache) generation(field string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generations[field]
+}
+
+// store writes the result of a call started at generation gen of the field,
+// unless the field was invalidated since.
+func (c *Cache) store(ctx context.Context, field, key string, gen uint64, res interface{}) {
+	value, err := json.Marshal(res)
+	if err == nil {
+		c.mu.Lock()
+		if c.generations[field] == gen {
+			err = c.Backend.Set(ctx, key, Entry{Value: value, StoredAt: time.Now()})
+		}
+		c.mu.Unlock()
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("cache write failed", "field", field, "error", err.Error())
+	}
+}
+
+// Invalidate drops every cached entry of the fields.
+func (c *Cache) Invalidate(ctx context.Context, fields ...string) {
+	if c == nil || c.Backend == nil {
+		return
+	}
+	c.mu.Lock()
+	if c.generations == nil {
+		c.generations = map[string]uint64{}
+	}
+	for _, field := range fields {
+		c.generations[field]++
+	}
+	c.mu.Unlock()
+	for _, field := range fields {
+		if err := c.Backend.DeletePrefix(ctx, field+":"); err != nil {
+			logging.FromContext(ctx).Error("cache invalidation failed", "field", field, "error", err.Error())
+		}
+	}
+}
+
+// Invalidating wraps a mutation resolver to invalidate the fields after it
+// succeeds.
+func (c *Cache) Invalidating(next graphql.FieldResolveFn, fields ...string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		res, err := next(p)
+		if err == nil {
+			c.Invalidate(p.Context, fields...)
+		}
+		return res, err
+	}
+}
+
+func key(field string, args map[string]interface{}) (string, error) {
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return field + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// decode returns the value newResult points to, since graphql resolves
+// lists from slices rather than pointers to them.
+func decode(value json.RawMessage, newResult func() interface{}) (interface{}, error) {
+	res := newResult()
+	if err := json.Unmarshal(value, res); err != nil {
+		return nil, err
+	}
+	return reflect.ValueOf(res).Elem().Interface(), nil
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+)
+
+// LRU is an in-memory Backend holding at most Size entries, evicting the
+// least recently used.
+type LRU struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry Entry
+}
+
+// NewLRU returns an LRU holding at most size entries, at least one.
+func NewLRU(size int) *LRU {
+	if size < 1 {
+		size = 1
+	}
+	return &LRU{size: size, order: list.New(), items: map[string]*list.Element{}}
+}
+
+func (c *LRU) Get(_ context.Context, key string) (Entry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return Entry{}, false, nil
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true, nil
+}
+
+func (c *LRU) Set(_ context.Context, key string, e Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruItem).entry = e
+		c.order.MoveToFront(el)
+		return nil
+	}
+	c.items[key] = c.order.PushFront(&lruItem{key: key, entry: e})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+	return nil
+}
+
+func (c *LRU) DeletePrefix(_ context.Context, prefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.order.Remove(el)
+			delete(c.items, key)
+		}
+	}
+	return nil
+}
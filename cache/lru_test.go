@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLRUSize(t *testing.T) {
+	ctx := context.Background()
+	for _, size := range []int{-1, 0, 1, 2} {
+		c := NewLRU(size)
+		for _, key := range []string{"a", "b", "c"} {
+			if err := c.Set(ctx, key, Entry{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want := size
+		if want < 1 {
+			want = 1
+		}
+		if len(c.items) != want || c.order.Len() != want {
+			t.Errorf("NewLRU(%d) holds %d entries, want %d", size, len(c.items), want)
+		}
+		if _, ok, _ := c.Get(ctx, "c"); !ok {
+			t.Errorf("NewLRU(%d) evicted the latest entry", size)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package cachecontrol lets shared caches such as a CDN store anonymous
+// GraphQL GET responses. Fields carry a max age hint, the response gets the
+// smallest hint of the fields it resolved and is only cacheable when every
+// root field has one and nothing failed.
+package cachecontrol
+
+import (
+	"context"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Directive declares @cacheControl(maxAge: Int!) in the schema so clients
+// can see it exists. Fields are annotated with Field, graphql-go neither
+// reads directives off field definitions nor exposes them in introspection,
+// so the SDL printer adds them from Hint. Object types are created with
+// Object for their hints to be found.
+var Directive = graphql.NewDirective(graphql.DirectiveConfig{
+	Name:        "cacheControl",
+	Description: "Seconds a shared cache may keep a response that includes the field.",
+	Locations:   []string{graphql.DirectiveLocationFieldDefinition},
+	Args: graphql.FieldConfigArgument{
+		"maxAge": &graphql.ArgumentConfig{
+			Type: graphql.NewNonNull(graphql.Int),
+		},
+	},
+})
+
+// graphql-go has no place on a field or type for extra data, so the hints
+// are kept beside the definitions, keyed by them rather than by name so that
+// schemas built side by side cannot see each other's hints.
+var (
+	mu      sync.Mutex
+	hints   = map[*graphql.Field]int{}
+	objects = map[graphql.Type]map[string]int{}
+)
+
+// Field annotates f with a max age in seconds. Fields without a hint leave
+// the response's max age alone, except root fields which make it
+// uncacheable.
+func Field(maxAge int, f *graphql.Field) *graphql.Field {
+	next := f.Resolve
+	if next == nil {
+		next = graphql.DefaultResolveFn
+	}
+	mu.Lock()
+	hints[f] = maxAge
+	mu.Unlock()
+	f.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+		if s := fromContext(p.Context); s != nil {
+			s.hint(maxAge, p.Info.Path.Prev == nil)
+		}
+		return next(p)
+	}
+	return f
+}
+
+// Object creates an object type and records the hints of its fields, so
+// that they can be found from the schema, whose field definitions are
+// copies of the fields in config.
+func Object(config graphql.ObjectConfig) *graphql.Object {
+	object := graphql.NewObject(config)
+	fields, _ := config.Fields.(graphql.Fields)
+	mu.Lock()
+	defer mu.Unlock()
+	for name, f := range fields {
+		if maxAge, ok := hints[f]; ok {
+			if objects[object] == nil {
+				objects[object] = map[string]int{}
+			}
+			objects[object][name] = maxAge
+		}
+	}
+	return object
+}
+
+// Hint returns the max age of the field fieldName of t.
+func Hint(t graphql.Type, fieldName string) (int, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	maxAge, ok := objects[t][fieldName]
+	return maxAge, ok
+}
+
+// state collects the hints of one request.
+type state struct {
+	mu          sync.Mutex
+	maxAge      int
+	hinted      bool
+	roots       int
+	hintedRoots int
+	failed      bool
+}
+
+func (s *state) hint(maxAge int, root bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.hinted || maxAge < s.maxAge {
+		s.maxAge = maxAge
+	}
+	s.hinted = true
+	if root {
+		s.hintedRoots++
+	}
+}
+
+// MaxAge is the number of seconds the response may be cached, 0 when it
+// must not be.
+func (s *state) MaxAge() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failed || s.roots == 0 || s.roots != s.hintedRoots || s.maxAge < 0 {
+		return 0
+	}
+	return s.maxAge
+}
+
+type contextKey struct{}
+
+func newContext(ctx context.Context, s *state) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+func fromContext(ctx context.Context) *state {
+	s, _ := ctx.Value(contextKey{}).(*state)
+	return s
+}
+
+// Extension counts root fields and failures for Middleware. Add it to the
+// schema with Schema.AddExtensions.
+type Extension struct{}
+
+func (Extension) Name() string {
+	return "cacheControl"
+}
+
+func (Extension) Init(ctx context.Context, p *graphql.Params) context.Context {
+	return ctx
+}
+
+func (Extension) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(error) {}
+}
+
+func (Extension) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func([]gqlerrors.FormattedError) {}
+}
+
+func (Extension) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(res *graphql.Result) {
+		if s := fromContext(ctx); s != nil && res.HasErrors() {
+			s.mu.Lock()
+			s.failed = true
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (Extension) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	if s := fromContext(ctx); s != nil && info.Path.Prev == nil {
+		s.mu.Lock()
+		s.roots++
+		s.mu.Unlock()
+	}
+	return ctx, func(interface{}, error) {}
+}
+
+func (Extension) HasResult() bool {
+	return false
+}
+
+func (Extension) GetResult(context.Context) interface{} {
+	return nil
+}
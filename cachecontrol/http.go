@@ -0,0 +1,91 @@
+package cachecontrol
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Middleware adds Cache-Control and ETag headers to GET requests. Requests
+// carrying credentials are marked private and never stored; anonymous ones
+// are buffered so the headers can reflect what the query resolved, and an
+// If-None-Match matching the ETag is answered with 304.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if authenticated(r) {
+			w.Header().Set("Cache-Control", "private, no-store")
+			next.ServeHTTP(w, r)
+			return
+		}
+		s := &state{}
+		rec := &recorder{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(newContext(r.Context(), s)))
+
+		for key, values := range rec.header {
+			w.Header()[key] = values
+		}
+		maxAge := s.MaxAge()
+		if rec.status != http.StatusOK || maxAge == 0 || rec.header.Get("Set-Cookie") != "" {
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+		sum := sha256.Sum256(rec.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+		// the same URL is answered privately when it carries credentials
+		w.Header().Add("Vary", "Cookie, Authorization")
+		w.Header().Set("ETag", etag)
+		if matches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+	})
+}
+
+func authenticated(r *http.Request) bool {
+	if r.Header.Get("Authorization") != "" {
+		return true
+	}
+	_, err := r.Cookie("jwtToken")
+	return err == nil
+}
+
+func matches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(candidate), "W/"))
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
@@ -0,0 +1,63 @@
+// Package clientstream tells client interceptors when a stream ends,
+// whether it is drained, fails or is abandoned with its context.
+package clientstream
+
+import (
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Watch wraps stream so that done is called once with the outcome of the
+// stream: nil when RecvMsg returns io.EOF, the error that ended RecvMsg, or
+// the status of the context of a stream closed before being drained.
+//
+// grpc cancels the stream context as a drained stream finishes, before
+// RecvMsg returns io.EOF, so the context only decides the outcome of
+// streams that nobody is receiving from when it ends.
+func Watch(stream grpc.ClientStream, done func(err error)) grpc.ClientStream {
+	s := &watchedStream{ClientStream: stream, done: done}
+	go s.abandoned()
+	return s
+}
+
+type watchedStream struct {
+	grpc.ClientStream
+	once sync.Once
+	done func(error)
+
+	mu        sync.Mutex
+	receiving bool
+}
+
+func (s *watchedStream) RecvMsg(m interface{}) error {
+	s.mu.Lock()
+	s.receiving = true
+	s.mu.Unlock()
+	err := s.ClientStream.RecvMsg(m)
+	if err == io.EOF {
+		s.end(nil)
+	} else if err != nil {
+		s.end(err)
+	}
+	s.mu.Lock()
+	s.receiving = false
+	s.mu.Unlock()
+	return err
+}
+
+func (s *watchedStream) abandoned() {
+	<-s.Context().Done()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// a pending RecvMsg returns the outcome itself
+	if !s.receiving {
+		s.end(status.FromContextError(s.Context().Err()).Err())
+	}
+}
+
+func (s *watchedStream) end(err error) {
+	s.once.Do(func() { s.done(err) })
+}
@@ -0,0 +1,86 @@
+package clientstream
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream returns the errors of recv in order, then blocks until its
+// context ends.
+type fakeStream struct {
+	grpc.ClientStream
+	ctx  context.Context
+	recv []error
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStream) RecvMsg(interface{}) error {
+	if len(s.recv) == 0 {
+		<-s.ctx.Done()
+		return status.FromContextError(s.ctx.Err()).Err()
+	}
+	err := s.recv[0]
+	s.recv = s.recv[1:]
+	return err
+}
+
+func TestWatch(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		recv []error
+		// receives is the number of RecvMsg calls before the context ends,
+		// the last one is left pending when it blocks
+		receives int
+		want     codes.Code
+	}{
+		{"drained", []error{nil, io.EOF}, 2, codes.OK},
+		{"failed", []error{status.Error(codes.Unavailable, "down")}, 1, codes.Unavailable},
+		{"abandoned", []error{nil, io.EOF}, 1, codes.Canceled},
+		{"canceled while receiving", nil, 1, codes.Canceled},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			var mu sync.Mutex
+			var outcomes []error
+			s := Watch(&fakeStream{ctx: ctx, recv: tc.recv}, func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				outcomes = append(outcomes, err)
+			})
+			received := make(chan struct{})
+			go func() {
+				defer close(received)
+				for i := 0; i < tc.receives; i++ {
+					s.RecvMsg(nil)
+				}
+			}()
+			if len(tc.recv) >= tc.receives {
+				<-received
+			} else {
+				time.Sleep(10 * time.Millisecond)
+			}
+			// the context of a drained stream ends too, after io.EOF
+			cancel()
+			<-received
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			if len(outcomes) != 1 {
+				t.Fatalf("done called with %v, want one outcome", outcomes)
+			}
+			if got := status.Code(outcomes[0]); got != tc.want {
+				t.Errorf("done called with %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
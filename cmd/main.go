@@ -2,45 +2,217 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/graphql-go/handler"
 	"github.com/joho/godotenv"
+	"github.com/vishnusunil243/api_gateway/audit"
+	"github.com/vishnusunil243/api_gateway/cache"
+	"github.com/vishnusunil243/api_gateway/gateway"
 	graph "github.com/vishnusunil243/api_gateway/graphql"
-	"github.com/vishnusunil243/api_gateway/middleware"
+	"github.com/vishnusunil243/api_gateway/idempotency"
+	"github.com/vishnusunil243/api_gateway/identity"
+	"github.com/vishnusunil243/api_gateway/lockout"
+	"github.com/vishnusunil243/api_gateway/logging"
+	"github.com/vishnusunil243/api_gateway/metrics"
+	"github.com/vishnusunil243/api_gateway/mock"
+	"github.com/vishnusunil243/api_gateway/ratelimit"
+	"github.com/vishnusunil243/api_gateway/replay"
+	"github.com/vishnusunil243/api_gateway/server"
+	"github.com/vishnusunil243/api_gateway/tracing"
+	"github.com/vishnusunil243/api_gateway/upstream"
 	"github.com/vishnusunil243/proto-files/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
-func main() {
-	productConn, err := grpc.Dial("localhost:8080", grpc.WithInsecure())
+func dialUpstream(name, prefix, defaultAddr string, opts ...grpc.DialOption) *grpc.ClientConn {
+	cfg, err := upstream.LoadConfig(name, prefix, defaultAddr)
 	if err != nil {
-		log.Println(err.Error())
+		log.Fatalf(err.Error())
 	}
-	userConn, err := grpc.Dial("localhost:8082", grpc.WithInsecure())
+	conn, err := upstream.Dial(cfg, opts...)
 	if err != nil {
-		log.Println(err.Error())
+		log.Fatalf(err.Error())
 	}
-	cartConn, err := grpc.Dial("localhost:8083", grpc.WithInsecure())
-	if err != nil {
-		log.Println(err.Error())
+	log.Println("upstream", cfg)
+	return conn
+}
+
+// auditSink builds the sinks listed in AUDIT_SINK ("file", "grpc" or both,
+// comma separated). The file sink is listed first so auditLog can read it.
+func auditSink(dialOpts ...grpc.DialOption) (audit.Sink, error) {
+	kinds := os.Getenv("AUDIT_SINK")
+	if kinds == "" {
+		kinds = "file"
+	}
+	var sinks audit.MultiSink
+	for _, kind := range strings.Split(kinds, ",") {
+		switch strings.TrimSpace(kind) {
+		case "file":
+			path := os.Getenv("AUDIT_FILE")
+			if path == "" {
+				path = "audit.log"
+			}
+			sink, err := audit.NewFileSink(path)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(audit.MultiSink{sink}, sinks...)
+		case "grpc":
+			conn := dialUpstream("audit", "AUDIT_SERVICE", "localhost:8086", dialOpts...)
+			sinks = append(sinks, audit.NewGRPCSink(conn))
+		default:
+			return nil, fmt.Errorf("unknown audit sink %q", kind)
+		}
 	}
-	orderConn, err := grpc.Dial("localhost:8084", grpc.WithInsecure())
+	return sinks, nil
+}
+
+func main() {
+	// --mock serves generated data instead of calling the upstreams, for
+	// working on a client without running the services
+	mockMode := flag.Bool("mock", false, "answer upstream calls with generated data")
+	mockSeed := flag.Int64("mock-seed", 1, "seed of the generated data")
+	mockFixtures := flag.String("mock-fixtures", "", "JSON file overriding generated responses")
+	flag.Parse()
+	if err := godotenv.Load("../.env"); err != nil {
+		log.Fatalf(err.Error())
+	}
+	logger := logging.New(os.Stdout, os.Getenv("LOG_LEVEL"))
+	slog.SetDefault(logger)
+	shutdownTracing, err := tracing.Setup(context.Background(), os.Getenv("TRACE_EXPORTER"))
 	if err != nil {
-		log.Println(err.Error())
+		log.Fatalf(err.Error())
 	}
-	wishlsitConn, err := grpc.Dial("localhost:8085", grpc.WithInsecure())
+	defer shutdownTracing(context.Background())
+	secretString := os.Getenv("SECRET")
+	secret := []byte(secretString)
+	// backends verifying identities must not hold the key that signs
+	// sessions
+	identitySecret := []byte(os.Getenv("IDENTITY_SECRET"))
+	switch {
+	case len(identitySecret) == 0 && *mockMode:
+		// the mocked upstreams do not verify identities
+		if identitySecret, err = mock.NewSecret(); err != nil {
+			log.Fatalf(err.Error())
+		}
+	case len(identitySecret) == 0:
+		log.Fatalf("IDENTITY_SECRET is required")
+	case string(identitySecret) == secretString:
+		log.Fatalf("IDENTITY_SECRET must differ from SECRET")
+	}
+	// UPSTREAM_RECORD appends the upstream traffic to a file that
+	// UPSTREAM_REPLAY later serves instead of dialing the upstreams
+	recordPath, replayPath := os.Getenv("UPSTREAM_RECORD"), os.Getenv("UPSTREAM_REPLAY")
+	if recordPath != "" && replayPath != "" {
+		log.Fatalf("UPSTREAM_RECORD and UPSTREAM_REPLAY cannot both be set")
+	}
+	if *mockMode && (recordPath != "" || replayPath != "") {
+		log.Fatalf("--mock cannot be used with UPSTREAM_RECORD or UPSTREAM_REPLAY")
+	}
+	var recorder *replay.Recorder
+	if recordPath != "" {
+		if recorder, err = replay.NewRecorder(recordPath); err != nil {
+			log.Fatalf(err.Error())
+		}
+		defer recorder.Close()
+	}
+	dialOpts := func(name string) []grpc.DialOption {
+		unary := []grpc.UnaryClientInterceptor{
+			tracing.UnaryClientInterceptor(name),
+			metrics.UnaryClientInterceptor(name),
+			logging.UnaryClientInterceptor(name),
+			identity.UnaryClientInterceptor(identitySecret),
+		}
+		stream := []grpc.StreamClientInterceptor{
+			tracing.StreamClientInterceptor(name),
+			metrics.StreamClientInterceptor(name),
+			logging.StreamClientInterceptor(name),
+			identity.StreamClientInterceptor(identitySecret),
+		}
+		if recorder != nil {
+			unary = append(unary, recorder.UnaryClientInterceptor(name))
+			stream = append(stream, recorder.StreamClientInterceptor(name))
+		}
+		return []grpc.DialOption{
+			grpc.WithChainUnaryInterceptor(unary...),
+			grpc.WithChainStreamInterceptor(stream...),
+		}
+	}
+	var replayConn *replay.Conn
+	if replayPath != "" {
+		calls, err := replay.Load(replayPath)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+		log.Println("replaying", len(calls), "upstream calls from", replayPath)
+		replayConn = replay.NewConn(calls)
+	}
+	orderIds, err := graph.ParseOrderIds(os.Getenv("ORDER_STATUS_IDS"), os.Getenv("PAYMENT_TYPE_IDS"))
 	if err != nil {
-		log.Println(err.Error())
+		log.Fatalf(err.Error())
+	}
+	var mockConn *mock.Conn
+	if *mockMode {
+		var fixtures *mock.Fixtures
+		if *mockFixtures != "" {
+			if fixtures, err = mock.LoadFixtures(*mockFixtures); err != nil {
+				log.Fatalf(err.Error())
+			}
+		}
+		log.Println("mocking the upstreams with seed", *mockSeed)
+		mockConn = mock.NewConn(*mockSeed, fixtures)
+		for _, ids := range []struct {
+			field protoreflect.FullName
+			ids   map[string]uint32
+		}{
+			{"order.GetAllOrderResponse.order_status_id", orderIds.Statuses},
+			{"order.GetAllOrderResponse.payment_type_id", orderIds.PaymentTypes},
+		} {
+			var choices []int64
+			for _, id := range ids.ids {
+				choices = append(choices, int64(id))
+			}
+			// sorted so that a seed generates the same data on every run
+			sort.Slice(choices, func(i, j int) bool { return choices[i] < choices[j] })
+			mockConn.Choices[ids.field] = choices
+		}
+		// /dev/token hands out tokens to anyone, they must not be valid
+		// against a gateway sharing SECRET
+		if secret, err = mock.NewSecret(); err != nil {
+			log.Fatalf(err.Error())
+		}
+	}
+	var conns []*grpc.ClientConn
+	connect := func(name, prefix, defaultAddr string) grpc.ClientConnInterface {
+		if replayConn != nil {
+			return replayConn
+		}
+		if mockConn != nil {
+			return mockConn
+		}
+		conn := dialUpstream(name, prefix, defaultAddr, dialOpts(name)...)
+		conns = append(conns, conn)
+		return conn
 	}
+	productConn := connect("product", "PRODUCT_SERVICE", "localhost:8080")
+	userConn := connect("user", "USER_SERVICE", "localhost:8082")
+	cartConn := connect("cart", "CART_SERVICE", "localhost:8083")
+	orderConn := connect("order", "ORDER_SERVICE", "localhost:8084")
+	wishlsitConn := connect("wishlist", "WISHLIST_SERVICE", "localhost:8085")
 	defer func() {
-		productConn.Close()
-		userConn.Close()
-		cartConn.Close()
-		orderConn.Close()
-		wishlsitConn.Close()
+		for _, conn := range conns {
+			conn.Close()
+		}
 	}()
 	productRes := pb.NewProductServiceClient(productConn)
 	userRes := pb.NewUserServiceClient(userConn)
@@ -48,26 +220,112 @@ func main() {
 	orderRes := pb.NewOrderServiceClient(orderConn)
 	wishlistRes := pb.NewWishlistServiceClient(wishlsitConn)
 
-	if err := godotenv.Load("../.env"); err != nil {
+	sink, err := auditSink(dialOpts("audit")...)
+	if err != nil {
 		log.Fatalf(err.Error())
 	}
-	secretString := os.Getenv("SECRET")
-	graph.Initialize(productRes, userRes, cartRes, orderRes, wishlistRes)
-	graph.RetrieveSecret(secretString)
-	middleware.InitMiddlewareSecret(secretString)
-
-	h := handler.New(&handler.Config{
-		Schema: &graph.Schema,
-		Pretty: true,
-	})
-	http.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
-		ctx := context.WithValue(r.Context(), "httpResponseWriter", w)
-		ctx = context.WithValue(ctx, "request", r)
+	auditor := &audit.Auditor{Sink: sink}
+	idempotencyTTL := 24 * time.Hour
+	if v := os.Getenv("IDEMPOTENCY_TTL"); v != "" {
+		if idempotencyTTL, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid IDEMPOTENCY_TTL: %s", err.Error())
+		}
+	}
+	idempotencyStore := idempotency.NewMemoryStore()
+	go idempotencyStore.SweepEvery(context.Background(), time.Minute)
+	keys := &idempotency.Keys{Store: idempotencyStore, TTL: idempotencyTTL}
+	policies, trustProxy, err := ratelimit.LoadConfig()
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	var limiter *ratelimit.Limiter
+	if os.Getenv("RATE_LIMIT") != "off" {
+		limiter = &ratelimit.Limiter{Store: ratelimit.NewMemoryStore(), Policies: policies, TrustProxy: trustProxy}
+	}
+	var responseCache *cache.Cache
+	if os.Getenv("CACHE") != "off" {
+		size := 1000
+		if v := os.Getenv("CACHE_SIZE"); v != "" {
+			if size, err = strconv.Atoi(v); err != nil {
+				log.Fatalf("invalid CACHE_SIZE: %s", err.Error())
+			}
+			if size < 1 {
+				log.Fatalf("invalid CACHE_SIZE: must be at least 1, got %d", size)
+			}
+		}
+		responseCache = &cache.Cache{Backend: cache.NewLRU(size), Policies: cache.DefaultPolicies()}
+	}
+	var lockouts *lockout.Tracker
+	if os.Getenv("LOGIN_LOCKOUT") != "off" {
+		lockoutStore := lockout.NewMemoryStore()
+		go lockoutStore.SweepEvery(context.Background(), time.Minute)
+		lockouts = &lockout.Tracker{Store: lockoutStore, Policies: lockout.DefaultPolicies(), TrustProxy: trustProxy}
+	}
 
-		r = r.WithContext(ctx)
+	// operation names sent by known clients, besides those of the REST
+	// routes, that get their own latency series
+	if v := os.Getenv("METRICS_OPERATIONS"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			metrics.RegisterOperations(strings.TrimSpace(name))
+		}
+	}
+	signupSaga := graph.NewSignupSaga(userRes, cartRes, wishlistRes)
+	deps := graph.Deps{
+		Products:        productRes,
+		Users:           userRes,
+		Carts:           cartRes,
+		Orders:          orderRes,
+		Wishlists:       wishlistRes,
+		Secret:          secret,
+		Modules:         os.Getenv("SCHEMA_MODULES"),
+		LegacyMutations: os.Getenv("LEGACY_MUTATIONS") != "false",
+		Signup:          signupSaga,
+		Cache:           responseCache,
+		Idempotency:     keys,
+		OrderIds:        orderIds,
+		RateLimiter:     limiter,
+		Lockouts:        lockouts,
+		Audit:           auditor,
+	}
+	schema, err := graph.NewSchema(deps)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	if err := limiter.Check(); err != nil {
+		log.Fatalf(err.Error())
+	}
+	go signupSaga.ResumeEvery(logging.NewContext(context.Background(), logger), time.Minute)
 
-		h.ContextHandler(ctx, w, r)
-	})
-	log.Println("listening on port 8081 of api gateway")
-	http.ListenAndServe(":8081", nil)
+	h, err := gateway.Handler(&schema, deps, logger)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	if *mockMode {
+		mux := http.NewServeMux()
+		mux.Handle("/", h)
+		mux.Handle("/dev/token", mock.TokenHandler(secret))
+		h = mux
+	}
+	// the metrics are served apart so that only the scraper can reach them,
+	// METRICS_ADDR=:9090 listens on every interface and off disables them
+	metricsAddr := os.Getenv("METRICS_ADDR")
+	if metricsAddr == "" {
+		metricsAddr = "127.0.0.1:9090"
+	}
+	if metricsAddr != "off" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", metrics.Handler())
+			if err := http.ListenAndServe(metricsAddr, mux); err != nil {
+				log.Fatalf("serving metrics: %s", err.Error())
+			}
+		}()
+	}
+	serverConfig, err := server.LoadConfig()
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	if err := server.ListenAndServe(serverConfig, h); err != nil {
+		log.Fatalf(err.Error())
+	}
 }
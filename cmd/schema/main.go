@@ -0,0 +1,59 @@
+// Command schema prints the gateway schema as SDL, writes the checked-in
+// snapshot and checks the current schema against it.
+//
+//	go run ./cmd/schema                       print the SDL
+//	go run ./cmd/schema -write schema.graphql update the snapshot
+//	go run ./cmd/schema -check schema.graphql fail on changes to the snapshot
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	graph "github.com/vishnusunil243/api_gateway/graphql"
+	"github.com/vishnusunil243/api_gateway/sdl"
+)
+
+func main() {
+	write := flag.String("write", "", "write the SDL to this file")
+	check := flag.String("check", "", "compare the schema with the SDL in this file")
+	flag.Parse()
+
+	// resolvers never run here, the schema needs no clients
+	schema, err := graph.NewSchema(graph.Deps{})
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	current := sdl.Print(&schema)
+	switch {
+	case *write != "":
+		if err := os.WriteFile(*write, []byte(current), 0644); err != nil {
+			log.Fatalf(err.Error())
+		}
+	case *check != "":
+		snapshot, err := os.ReadFile(*check)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+		if string(snapshot) == current {
+			return
+		}
+		changes, err := sdl.Diff(string(snapshot), current)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+		if breaking := sdl.Breaking(changes); len(breaking) > 0 {
+			fmt.Printf("%d breaking change(s) against %s\n", len(breaking), *check)
+		} else {
+			fmt.Printf("%s is out of date, run go run ./cmd/schema -write %s\n", *check, *check)
+		}
+		os.Exit(1)
+	default:
+		fmt.Print(current)
+	}
+}
@@ -0,0 +1,126 @@
+package fakes
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type CartServer struct {
+	pb.UnimplementedCartServiceServer
+
+	products *ProductServer
+
+	mu     sync.Mutex
+	nextId uint32
+	ids    map[uint32]uint32
+	// items maps a user to the quantity of each product in their cart
+	items map[uint32]map[uint32]int32
+}
+
+func NewCartServer(products *ProductServer) *CartServer {
+	return &CartServer{
+		products: products,
+		ids:      map[uint32]uint32{},
+		items:    map[uint32]map[uint32]int32{},
+	}
+}
+
+// Items returns the cart of a user with the totals at current prices.
+func (s *CartServer) Items(userId uint32) ([]*pb.GetAllCartResponse, error) {
+	s.mu.Lock()
+	items, ok := s.items[userId]
+	if !ok {
+		s.mu.Unlock()
+		return nil, status.Errorf(codes.NotFound, "user %d has no cart", userId)
+	}
+	var res []*pb.GetAllCartResponse
+	for productId, quantity := range items {
+		res = append(res, &pb.GetAllCartResponse{UserId: userId, ProductId: productId, Quantity: quantity})
+	}
+	s.mu.Unlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].ProductId < res[j].ProductId })
+	for _, item := range res {
+		product, err := s.products.get(item.ProductId)
+		if err != nil {
+			return nil, err
+		}
+		item.Total = float32(product.Price * item.Quantity)
+	}
+	return res, nil
+}
+
+func (s *CartServer) response(userId uint32) *pb.CartResponse {
+	return &pb.CartResponse{CartId: s.ids[userId], UserId: userId, IsEmpty: len(s.items[userId]) == 0}
+}
+
+func (s *CartServer) CreateCart(ctx context.Context, req *pb.UserCartCreate) (*pb.CartResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.ids[req.UserId]; !ok {
+		s.nextId++
+		s.ids[req.UserId] = s.nextId
+		s.items[req.UserId] = map[uint32]int32{}
+	}
+	return s.response(req.UserId), nil
+}
+
+func (s *CartServer) AddToCart(ctx context.Context, req *pb.AddToCartRequest) (*pb.CartResponse, error) {
+	product, err := s.products.get(req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items, ok := s.items[req.UserId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "user %d has no cart", req.UserId)
+	}
+	if items[req.ProductId]+req.Quantity > product.Quantity {
+		return nil, status.Errorf(codes.FailedPrecondition, "only %d of %s left in stock", product.Quantity, product.Name)
+	}
+	items[req.ProductId] += req.Quantity
+	return s.response(req.UserId), nil
+}
+
+func (s *CartServer) RemoveFromCart(ctx context.Context, req *pb.RemoveFromCartRequest) (*pb.CartResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items, ok := s.items[req.UserId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "user %d has no cart", req.UserId)
+	}
+	if _, ok := items[req.ProductId]; !ok {
+		return nil, status.Errorf(codes.NotFound, "product %d is not in the cart", req.ProductId)
+	}
+	delete(items, req.ProductId)
+	return s.response(req.UserId), nil
+}
+
+func (s *CartServer) GetAllCartItems(req *pb.UserCartCreate, stream pb.CartService_GetAllCartItemsServer) error {
+	items, err := s.Items(req.UserId)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := stream.Send(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *CartServer) TruncateCart(ctx context.Context, req *pb.UserCartCreate) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[req.UserId]; !ok {
+		return nil, status.Errorf(codes.NotFound, "user %d has no cart", req.UserId)
+	}
+	s.items[req.UserId] = map[uint32]int32{}
+	return &emptypb.Empty{}, nil
+}
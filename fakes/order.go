@@ -0,0 +1,142 @@
+package fakes
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Status ids as stored by the order service.
+const (
+	OrderStatusPending   uint32 = 1
+	OrderStatusCancelled uint32 = 5
+
+	PaymentTypeCashOnDelivery uint32 = 1
+)
+
+type OrderServer struct {
+	pb.UnimplementedOrderServiceServer
+
+	carts    *CartServer
+	products *ProductServer
+	users    *UserServer
+
+	mu     sync.Mutex
+	nextId uint32
+	orders map[uint32]*pb.GetAllOrderResponse
+	owners map[uint32]uint32
+}
+
+func NewOrderServer(carts *CartServer, products *ProductServer, users *UserServer) *OrderServer {
+	return &OrderServer{
+		carts:    carts,
+		products: products,
+		users:    users,
+		orders:   map[uint32]*pb.GetAllOrderResponse{},
+		owners:   map[uint32]uint32{},
+	}
+}
+
+// OrderAll orders everything in the user's cart for delivery to their
+// address, taking the items out of stock and emptying the cart.
+func (s *OrderServer) OrderAll(ctx context.Context, req *pb.OrderRequest) (*pb.OrderResponse, error) {
+	address, err := s.users.GetAddress(ctx, &pb.GetUserById{Id: req.UserId})
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, "add an address before ordering")
+	}
+	items, err := s.carts.Items(req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "the cart is empty")
+	}
+	for _, item := range items {
+		if _, err := s.products.UpdateQuantity(ctx, &pb.UpdateQuantityRequest{Id: item.ProductId, Quantity: item.Quantity}); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.carts.TruncateCart(ctx, &pb.UserCartCreate{UserId: req.UserId}); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextId++
+	order := &pb.GetAllOrderResponse{
+		OrderId:       s.nextId,
+		AddressId:     address.Id,
+		PaymentTypeId: PaymentTypeCashOnDelivery,
+		OrderStatusId: OrderStatusPending,
+	}
+	for _, item := range items {
+		order.OrderItems = append(order.OrderItems, &pb.OrderItems{
+			Id:       item.ProductId,
+			OrderId:  order.OrderId,
+			Quantity: item.Quantity,
+			Total:    float64(item.Total),
+		})
+	}
+	s.orders[order.OrderId] = order
+	s.owners[order.OrderId] = req.UserId
+	return &pb.OrderResponse{OrderId: order.OrderId}, nil
+}
+
+func (s *OrderServer) setStatus(orderId, statusId uint32) (*pb.OrderResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order, ok := s.orders[orderId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "order %d not found", orderId)
+	}
+	order.OrderStatusId = statusId
+	return &pb.OrderResponse{OrderId: orderId}, nil
+}
+
+func (s *OrderServer) UserCancelOrder(ctx context.Context, req *pb.OrderResponse) (*pb.OrderResponse, error) {
+	return s.setStatus(req.OrderId, OrderStatusCancelled)
+}
+
+func (s *OrderServer) ChangeOrderStatus(ctx context.Context, req *pb.ChangeOrderStatusRequest) (*pb.OrderResponse, error) {
+	return s.setStatus(req.OrderId, req.StatusId)
+}
+
+func (s *OrderServer) list(send func(*pb.GetAllOrderResponse) error, match func(userId uint32) bool) error {
+	s.mu.Lock()
+	var res []*pb.GetAllOrderResponse
+	for id, order := range s.orders {
+		if match(s.owners[id]) {
+			res = append(res, proto.Clone(order).(*pb.GetAllOrderResponse))
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].OrderId < res[j].OrderId })
+	for _, order := range res {
+		if err := send(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *OrderServer) GetAllOrdersUser(req *pb.OrderRequest, stream pb.OrderService_GetAllOrdersUserServer) error {
+	return s.list(stream.Send, func(userId uint32) bool { return userId == req.UserId })
+}
+
+func (s *OrderServer) GetAllOrders(_ *pb.NoParam, stream pb.OrderService_GetAllOrdersServer) error {
+	return s.list(stream.Send, func(uint32) bool { return true })
+}
+
+func (s *OrderServer) GetOrder(ctx context.Context, req *pb.OrderResponse) (*pb.GetAllOrderResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order, ok := s.orders[req.OrderId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "order %d not found", req.OrderId)
+	}
+	return proto.Clone(order).(*pb.GetAllOrderResponse), nil
+}
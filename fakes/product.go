@@ -0,0 +1,98 @@
+package fakes
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type ProductServer struct {
+	pb.UnimplementedProductServiceServer
+
+	mu       sync.Mutex
+	nextId   uint32
+	products map[uint32]*pb.AddProductResponse
+}
+
+func NewProductServer() *ProductServer {
+	return &ProductServer{products: map[uint32]*pb.AddProductResponse{}}
+}
+
+// Add stores a product and returns it with its id.
+func (s *ProductServer) Add(name string, price, quantity int32) *pb.AddProductResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextId++
+	p := &pb.AddProductResponse{Id: s.nextId, Name: name, Price: price, Quantity: quantity}
+	s.products[p.Id] = p
+	return proto.Clone(p).(*pb.AddProductResponse)
+}
+
+func (s *ProductServer) get(id uint32) (*pb.AddProductResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.products[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "product %d not found", id)
+	}
+	return proto.Clone(p).(*pb.AddProductResponse), nil
+}
+
+func (s *ProductServer) AddProduct(ctx context.Context, req *pb.AddProductRequest) (*pb.AddProductResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "product name is required")
+	}
+	s.mu.Lock()
+	for _, p := range s.products {
+		if p.Name == req.Name {
+			s.mu.Unlock()
+			return nil, status.Errorf(codes.AlreadyExists, "product %s already exists", req.Name)
+		}
+	}
+	s.mu.Unlock()
+	return s.Add(req.Name, req.Price, req.Quantity), nil
+}
+
+func (s *ProductServer) GetAllProducts(_ *emptypb.Empty, stream pb.ProductService_GetAllProductsServer) error {
+	s.mu.Lock()
+	var products []*pb.AddProductResponse
+	for _, p := range s.products {
+		products = append(products, proto.Clone(p).(*pb.AddProductResponse))
+	}
+	s.mu.Unlock()
+	sort.Slice(products, func(i, j int) bool { return products[i].Id < products[j].Id })
+	for _, p := range products {
+		if err := stream.Send(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ProductServer) GetProduct(ctx context.Context, req *pb.GetProductById) (*pb.AddProductResponse, error) {
+	return s.get(uint32(req.Id))
+}
+
+func (s *ProductServer) UpdateQuantity(ctx context.Context, req *pb.UpdateQuantityRequest) (*pb.AddProductResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.products[req.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "product %d not found", req.Id)
+	}
+	if req.Increase {
+		p.Quantity += req.Quantity
+	} else {
+		if p.Quantity < req.Quantity {
+			return nil, status.Errorf(codes.FailedPrecondition, "only %d left in stock", p.Quantity)
+		}
+		p.Quantity -= req.Quantity
+	}
+	return proto.Clone(p).(*pb.AddProductResponse), nil
+}
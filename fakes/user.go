@@ -0,0 +1,188 @@
+package fakes
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	RoleUser       = "user"
+	RoleAdmin      = "admin"
+	RoleSuperAdmin = "superadmin"
+)
+
+type account struct {
+	id       uint32
+	name     string
+	email    string
+	password string
+	role     string
+}
+
+func (a *account) response() *pb.UserSignupResponse {
+	return &pb.UserSignupResponse{Id: a.id, Name: a.name, Email: a.email}
+}
+
+type UserServer struct {
+	pb.UnimplementedUserServiceServer
+
+	mu        sync.Mutex
+	nextId    uint32
+	accounts  map[uint32]*account
+	addresses map[uint32]*pb.GetAddressResponse
+}
+
+func NewUserServer() *UserServer {
+	return &UserServer{
+		accounts:  map[uint32]*account{},
+		addresses: map[uint32]*pb.GetAddressResponse{},
+	}
+}
+
+// Add stores an account with one of the Role constants.
+func (s *UserServer) Add(name, email, password, role string) (*pb.UserSignupResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.accounts {
+		if strings.EqualFold(a.email, email) {
+			return nil, status.Errorf(codes.AlreadyExists, "an account with email %s already exists", email)
+		}
+	}
+	s.nextId++
+	a := &account{id: s.nextId, name: name, email: email, password: password, role: role}
+	s.accounts[a.id] = a
+	return a.response(), nil
+}
+
+func (s *UserServer) login(req *pb.UserLoginRequest, roles ...string) (*pb.UserSignupResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.accounts {
+		if !strings.EqualFold(a.email, req.Email) || a.password != req.Password {
+			continue
+		}
+		for _, role := range roles {
+			if a.role == role {
+				return a.response(), nil
+			}
+		}
+	}
+	return nil, status.Error(codes.Unauthenticated, "invalid email or password")
+}
+
+func (s *UserServer) get(id uint32, roles ...string) (*pb.UserSignupResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.accounts[id]; ok {
+		for _, role := range roles {
+			if a.role == role {
+				return a.response(), nil
+			}
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "%s %d not found", roles[0], id)
+}
+
+func (s *UserServer) list(send func(*pb.UserSignupResponse) error, roles ...string) error {
+	s.mu.Lock()
+	var res []*pb.UserSignupResponse
+	for _, a := range s.accounts {
+		for _, role := range roles {
+			if a.role == role {
+				res = append(res, a.response())
+			}
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].Id < res[j].Id })
+	for _, u := range res {
+		if err := send(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *UserServer) UserSignup(ctx context.Context, req *pb.UserSignupRequest) (*pb.UserSignupResponse, error) {
+	return s.Add(req.Name, req.Email, req.Password, RoleUser)
+}
+
+func (s *UserServer) UserLogin(ctx context.Context, req *pb.UserLoginRequest) (*pb.UserSignupResponse, error) {
+	return s.login(req, RoleUser)
+}
+
+func (s *UserServer) AdminLogin(ctx context.Context, req *pb.UserLoginRequest) (*pb.UserSignupResponse, error) {
+	return s.login(req, RoleAdmin, RoleSuperAdmin)
+}
+
+func (s *UserServer) SuperAdminLogin(ctx context.Context, req *pb.UserLoginRequest) (*pb.UserSignupResponse, error) {
+	return s.login(req, RoleSuperAdmin)
+}
+
+func (s *UserServer) GetAllUsers(_ *emptypb.Empty, stream pb.UserService_GetAllUsersServer) error {
+	return s.list(stream.Send, RoleUser)
+}
+
+func (s *UserServer) GetAllAdmins(_ *emptypb.Empty, stream pb.UserService_GetAllAdminsServer) error {
+	return s.list(stream.Send, RoleAdmin, RoleSuperAdmin)
+}
+
+func (s *UserServer) AddAdmin(ctx context.Context, req *pb.UserSignupRequest) (*pb.UserSignupResponse, error) {
+	return s.Add(req.Name, req.Email, req.Password, RoleAdmin)
+}
+
+func (s *UserServer) GetAdmin(ctx context.Context, req *pb.GetUserById) (*pb.UserSignupResponse, error) {
+	return s.get(req.Id, RoleAdmin, RoleSuperAdmin)
+}
+
+func (s *UserServer) GetUser(ctx context.Context, req *pb.GetUserById) (*pb.UserSignupResponse, error) {
+	return s.get(req.Id, RoleUser)
+}
+
+func (s *UserServer) AddAddress(ctx context.Context, req *pb.AddAddressRequest) (*pb.GetUserById, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[req.UserId]; !ok {
+		return nil, status.Errorf(codes.NotFound, "user %d not found", req.UserId)
+	}
+	if _, ok := s.addresses[req.UserId]; ok {
+		return nil, status.Error(codes.AlreadyExists, "an address has already been added")
+	}
+	s.addresses[req.UserId] = &pb.GetAddressResponse{
+		Id:       req.UserId,
+		UserId:   req.UserId,
+		City:     req.City,
+		State:    req.State,
+		Road:     req.Road,
+		District: req.District,
+	}
+	return &pb.GetUserById{Id: req.UserId}, nil
+}
+
+func (s *UserServer) RemoveAddress(ctx context.Context, req *pb.GetUserById) (*pb.GetUserById, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.addresses[req.Id]; !ok {
+		return nil, status.Error(codes.NotFound, "no address has been added")
+	}
+	delete(s.addresses, req.Id)
+	return &pb.GetUserById{Id: req.Id}, nil
+}
+
+func (s *UserServer) GetAddress(ctx context.Context, req *pb.GetUserById) (*pb.GetAddressResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	address, ok := s.addresses[req.Id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "no address has been added")
+	}
+	return proto.Clone(address).(*pb.GetAddressResponse), nil
+}
@@ -0,0 +1,77 @@
+package fakes
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type WishlistServer struct {
+	pb.UnimplementedWishlistServiceServer
+
+	mu    sync.Mutex
+	items map[uint32]map[uint32]bool
+}
+
+func NewWishlistServer() *WishlistServer {
+	return &WishlistServer{items: map[uint32]map[uint32]bool{}}
+}
+
+func (s *WishlistServer) CreateWishlist(ctx context.Context, req *pb.CreateWishlistRequest) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[req.UserId]; !ok {
+		s.items[req.UserId] = map[uint32]bool{}
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *WishlistServer) AddToWishlist(ctx context.Context, req *pb.AddToWishlistRequest) (*pb.CreateWishlistRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items, ok := s.items[req.UserId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "user %d has no wishlist", req.UserId)
+	}
+	if items[req.ProductId] {
+		return nil, status.Errorf(codes.AlreadyExists, "product %d is already in the wishlist", req.ProductId)
+	}
+	items[req.ProductId] = true
+	return &pb.CreateWishlistRequest{UserId: req.UserId}, nil
+}
+
+func (s *WishlistServer) RemoveFromWishlist(ctx context.Context, req *pb.AddToWishlistRequest) (*pb.CreateWishlistRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.items[req.UserId][req.ProductId] {
+		return nil, status.Errorf(codes.NotFound, "product %d is not in the wishlist", req.ProductId)
+	}
+	delete(s.items[req.UserId], req.ProductId)
+	return &pb.CreateWishlistRequest{UserId: req.UserId}, nil
+}
+
+func (s *WishlistServer) GetAllWishlistItems(req *pb.CreateWishlistRequest, stream pb.WishlistService_GetAllWishlistItemsServer) error {
+	s.mu.Lock()
+	items, ok := s.items[req.UserId]
+	if !ok {
+		s.mu.Unlock()
+		return status.Errorf(codes.NotFound, "user %d has no wishlist", req.UserId)
+	}
+	var productIds []uint32
+	for productId := range items {
+		productIds = append(productIds, productId)
+	}
+	s.mu.Unlock()
+	sort.Slice(productIds, func(i, j int) bool { return productIds[i] < productIds[j] })
+	for _, productId := range productIds {
+		if err := stream.Send(&pb.GetAllWishlistResponse{ProductId: productId}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
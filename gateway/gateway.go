@@ -0,0 +1,67 @@
+// Package gateway serves a schema over HTTP: the GraphQL endpoint and the
+// REST facade with its OpenAPI document. The metrics are served on a
+// listener of their own.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/handler"
+	"github.com/vishnusunil243/api_gateway/cachecontrol"
+	graph "github.com/vishnusunil243/api_gateway/graphql"
+	"github.com/vishnusunil243/api_gateway/logging"
+	"github.com/vishnusunil243/api_gateway/metrics"
+	"github.com/vishnusunil243/api_gateway/requestid"
+	"github.com/vishnusunil243/api_gateway/rest"
+	"github.com/vishnusunil243/api_gateway/tracing"
+)
+
+// Handler adds the instrumentation extensions to schema and routes the
+// gateway's endpoints to it. deps are those the schema was built with, the
+// REST routes of the modules it left out are left out too.
+func Handler(schema *graphql.Schema, deps graph.Deps, logger *slog.Logger) (http.Handler, error) {
+	schema.AddExtensions(metrics.Extension{}, tracing.Extension{}, logging.Extension{}, cachecontrol.Extension{})
+	h := handler.New(&handler.Config{
+		Schema: schema,
+		Pretty: true,
+	})
+	mux := http.NewServeMux()
+	var graphqlHandler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "httpResponseWriter", w)
+		ctx = context.WithValue(ctx, "request", r)
+
+		r = r.WithContext(ctx)
+
+		h.ContextHandler(ctx, w, r)
+	})
+	// wrapped inside out: the first middleware applied runs last
+	chain := func(h http.Handler) http.Handler {
+		h = cachecontrol.Middleware(h)
+		h = deps.RateLimiter.Middleware(h)
+		h = logging.Middleware(logger)(h)
+		h = requestid.Middleware(h)
+		h = tracing.HTTPMiddleware(h)
+		return metrics.InFlight(h)
+	}
+	mux.Handle("/graphql", chain(queriesOnly(graphqlHandler)))
+
+	facade, err := rest.New(schema, rest.Enabled(rest.Routes, deps.Modules))
+	if err != nil {
+		return nil, err
+	}
+	metrics.RegisterOperations(facade.Operations()...)
+	openAPI, err := json.Marshal(facade.OpenAPI("api_gateway", "v1"))
+	if err != nil {
+		return nil, err
+	}
+	mux.Handle("/api/v1/", chain(facade))
+	mux.HandleFunc("/api/v1/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	return mux, nil
+}
@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	graph "github.com/vishnusunil243/api_gateway/graphql"
+	"github.com/vishnusunil243/api_gateway/ratelimit"
+)
+
+func TestHandlerModules(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tc := range []struct {
+		modules string
+		path    string
+		served  bool
+	}{
+		{"", "/api/v1/cart/items", true},
+		{"products", "/api/v1/products", true},
+		{"products", "/api/v1/cart/items", false},
+		{"users,orders", "/api/v1/orders", true},
+		{"users,orders", "/api/v1/wishlist/items", false},
+		{"admin", "/api/v1/products", false},
+		{"", "/metrics", false},
+	} {
+		deps := graph.Deps{Modules: tc.modules}
+		schema, err := graph.NewSchema(deps)
+		if err != nil {
+			t.Fatalf("modules %q: %s", tc.modules, err.Error())
+		}
+		h, err := Handler(&schema, deps, logger)
+		if err != nil {
+			t.Errorf("modules %q: %s", tc.modules, err.Error())
+			continue
+		}
+		// the routes served need a session or a backend, only whether one
+		// matched is checked
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, tc.path, nil))
+		if served := w.Code != http.StatusNotFound; served != tc.served {
+			t.Errorf("modules %q: %s answered %d", tc.modules, tc.path, w.Code)
+		}
+	}
+}
+
+func TestHandlerLimitsPerInstance(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	serve := func() http.Handler {
+		deps := graph.Deps{RateLimiter: &ratelimit.Limiter{
+			Store:    ratelimit.NewMemoryStore(),
+			Policies: ratelimit.Policies{IP: ratelimit.Policy{Rate: 0.001, Burst: 1}},
+		}}
+		schema, err := graph.NewSchema(deps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := Handler(&schema, deps, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	first, second := serve(), serve()
+	for i, h := range []http.Handler{first, first, second} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/graphql", nil))
+		if limited := w.Code == http.StatusTooManyRequests; limited != (i == 1) {
+			t.Errorf("request %d answered %d", i, w.Code)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/handler"
+)
+
+// queriesOnly answers GET requests for a mutation with 405. A GET can be
+// sent cross-site by a link or an image, which SameSite=Lax cookies do not
+// stop, so it must not change state. Documents that fail to parse are left
+// for the handler to report.
+func queriesOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		opts := handler.NewRequestOptions(r)
+		doc, err := parser.Parse(parser.ParseParams{Source: opts.Query})
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for _, def := range doc.Definitions {
+			op, ok := def.(*ast.OperationDefinition)
+			if !ok || op.Operation == ast.OperationTypeQuery {
+				continue
+			}
+			if opts.OperationName == "" || (op.Name != nil && op.Name.Value == opts.OperationName) {
+				w.Header().Set("Allow", "POST")
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"errors": []gqlerrors.FormattedError{{
+						Message:    op.Operation + " operations must be sent with POST",
+						Extensions: map[string]interface{}{"code": "METHOD_NOT_ALLOWED"},
+					}},
+				})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
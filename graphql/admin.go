@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/audit"
+	"github.com/vishnusunil243/api_gateway/lockout"
+	"github.com/vishnusunil243/api_gateway/middleware"
+	"github.com/vishnusunil243/api_gateway/ratelimit"
+	"github.com/vishnusunil243/api_gateway/validate"
+)
+
+var AuditEventType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "AuditEvent",
+		Fields: graphql.Fields{
+			"time": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"actorId": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"roles": &graphql.Field{
+				Type: graphql.NewList(graphql.String),
+			},
+			"operation": &graphql.Field{
+				Type: graphql.String,
+			},
+			"arguments": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Arguments of the operation as JSON, with sensitive values redacted.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					args, err := json.Marshal(p.Source.(audit.Event).Arguments)
+					if err != nil {
+						return nil, err
+					}
+					return string(args), nil
+				},
+			},
+			"outcome": &graphql.Field{
+				Type: graphql.String,
+			},
+			"error": &graphql.Field{
+				Type: graphql.String,
+			},
+			"requestId": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	},
+)
+
+// Admin serves the gateway's own audit log and login lockouts, it has no
+// upstream service.
+type Admin struct {
+	Auth     *middleware.Auth
+	Limiter  *ratelimit.Limiter
+	Lockouts *lockout.Tracker
+	Audit    *audit.Auditor
+}
+
+func (m *Admin) Name() string {
+	return "admin"
+}
+
+func (m *Admin) Register(b *Builder) {
+	limit := validate.Arg(graphql.Int, validate.Range(1, audit.MaxLimit))
+	limit.DefaultValue = 100
+	b.Query("auditLog", validate.Field(&graphql.Field{
+		Type: graphql.NewList(AuditEventType),
+		Args: graphql.FieldConfigArgument{
+			"actorId": &graphql.ArgumentConfig{
+				Type: graphql.Int,
+			},
+			"operation": &graphql.ArgumentConfig{
+				Type: graphql.String,
+			},
+			"outcome": &graphql.ArgumentConfig{
+				Type: graphql.String,
+			},
+			"since": &graphql.ArgumentConfig{
+				Type: graphql.DateTime,
+			},
+			"until": &graphql.ArgumentConfig{
+				Type: graphql.DateTime,
+			},
+			"limit": limit,
+		},
+		Resolve: m.Auth.SuperAdminMiddleware(m.Limiter.Operation("auditLog", func(p graphql.ResolveParams) (interface{}, error) {
+			filter := audit.Filter{}
+			if actorId, ok := p.Args["actorId"].(int); ok {
+				filter.ActorID = uint32(actorId)
+			}
+			filter.Operation, _ = p.Args["operation"].(string)
+			filter.Outcome, _ = p.Args["outcome"].(string)
+			if since, ok := p.Args["since"].(time.Time); ok {
+				filter.Since = since
+			}
+			if until, ok := p.Args["until"].(time.Time); ok {
+				filter.Until = until
+			}
+			filter.Limit, _ = p.Args["limit"].(int)
+			return m.Audit.Query(p.Context, filter)
+		})),
+	}))
+	b.Query("loginLockouts", &graphql.Field{
+		Type:        graphql.NewList(LoginLockoutType),
+		Description: "Logins with recent failures or an active lock, most recent first.",
+		Resolve: m.Auth.AdminMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			return m.Lockouts.List(p.Context)
+		}),
+	})
+
+	b.Mutation("clearLoginLockout", validate.Field(&graphql.Field{
+		Type: graphql.Boolean,
+		Args: graphql.FieldConfigArgument{
+			"kind": &graphql.ArgumentConfig{
+				Type: graphql.NewNonNull(LoginKindEnum),
+			},
+			"scope": &graphql.ArgumentConfig{
+				Type: graphql.NewNonNull(LockoutScopeEnum),
+			},
+			"value": validate.Arg(graphql.NewNonNull(graphql.String), validate.Required(), validate.Length(1, 255)),
+		},
+		Resolve: m.Auth.AdminMiddleware(m.Audit.Privileged("clearLoginLockout", func(p graphql.ResolveParams) (interface{}, error) {
+			kind := p.Args["kind"].(string)
+			if err := canManageLockout(p, kind); err != nil {
+				return nil, err
+			}
+			err := m.Lockouts.Clear(p.Context, lockout.State{
+				Kind:  kind,
+				Scope: p.Args["scope"].(string),
+				Value: p.Args["value"].(string),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return true, nil
+		})),
+	}))
+}
@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"io"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/middleware"
+	"github.com/vishnusunil243/api_gateway/validate"
+	"github.com/vishnusunil243/proto-files/pb"
+)
+
+var CartType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "cart",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"userId": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"productId": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"quantity": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"total": &graphql.Field{
+				Type: graphql.Float,
+			},
+		},
+	},
+)
+
+// Cart serves the cart of the logged in user.
+type Cart struct {
+	Client pb.CartServiceClient
+	Auth   *middleware.Auth
+}
+
+func (m *Cart) Name() string {
+	return "cart"
+}
+
+func (m *Cart) Register(b *Builder) {
+	b.Query("GetAllCartItems", &graphql.Field{
+		Type: graphql.NewList(CartType),
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			userIdVal := p.Context.Value("userId").(uint)
+			cartItems, err := m.Client.GetAllCartItems(p.Context, &pb.UserCartCreate{
+				UserId: uint32(userIdVal),
+			})
+			if err != nil {
+				return nil, err
+			}
+			var res []*pb.GetAllCartResponse
+			for {
+				item, err := cartItems.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, item)
+			}
+			return res, nil
+		}),
+	})
+
+	b.Mutation("AddToCart", validate.Field(&graphql.Field{
+		Type: CartType,
+		Args: graphql.FieldConfigArgument{
+			"productId": validate.Arg(graphql.NewNonNull(graphql.Int), validate.Min(1)),
+			"quantity":  validate.Arg(graphql.NewNonNull(graphql.Int), validate.Range(1, 100)),
+		},
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			userIDval := p.Context.Value("userId").(uint)
+			return m.Client.AddToCart(p.Context, &pb.AddToCartRequest{
+				UserId:    uint32(userIDval),
+				ProductId: uint32(p.Args["productId"].(int)),
+				Quantity:  int32(p.Args["quantity"].(int)),
+			})
+		}),
+	}))
+	b.Mutation("RemoveFromCart", validate.Field(&graphql.Field{
+		Type: CartType,
+		Args: graphql.FieldConfigArgument{
+			"productId": validate.Arg(graphql.NewNonNull(graphql.Int), validate.Min(1)),
+		},
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			userIdVal := p.Context.Value("userId").(uint)
+			return m.Client.RemoveFromCart(p.Context, &pb.RemoveFromCartRequest{
+				UserId:    uint32(userIdVal),
+				ProductId: uint32(p.Args["productId"].(int)),
+			})
+		}),
+	}))
+}
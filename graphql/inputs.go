@@ -0,0 +1,162 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/validate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// legacyMutation keeps the flat argument forms of AddAddress, AddProduct
+// and UserSignup callable, while enabled, as clients move to the input
+// based mutations.
+func legacyMutation(enabled bool, replacement string, next graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if !enabled {
+			return nil, fmt.Errorf("%s has been removed, use %s instead", p.Info.FieldName, replacement)
+		}
+		return next(p)
+	}
+}
+
+var AddressInputType = validate.InputObject(
+	graphql.InputObjectConfig{
+		Name: "AddressInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"city": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"district": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"state": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"road": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+		},
+	},
+	validate.Rules{
+		"city":     {validate.Required(), validate.Length(1, 100)},
+		"district": {validate.Required(), validate.Length(1, 100)},
+		"state":    {validate.Required(), validate.Length(1, 100)},
+		"road":     {validate.Required(), validate.Length(1, 100)},
+	},
+)
+var ProductInputType = validate.InputObject(
+	graphql.InputObjectConfig{
+		Name: "ProductInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"price": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.Int),
+			},
+			"quantity": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.Int),
+			},
+		},
+	},
+	validate.Rules{
+		"name":     {validate.Required(), validate.Length(1, 200)},
+		"price":    {validate.Range(1, 10000000)},
+		"quantity": {validate.Range(0, 100000)},
+	},
+)
+var SignupInputType = validate.InputObject(
+	graphql.InputObjectConfig{
+		Name: "SignupInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"email": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"password": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"idempotencyKey": &graphql.InputObjectFieldConfig{
+				Type:        graphql.String,
+				Description: "Retrying with the same key and input finishes an interrupted signup instead of failing on the existing user. Without a key signups are not deduplicated.",
+			},
+		},
+	},
+	validate.Rules{
+		"idempotencyKey": {validate.Length(0, 128)},
+		"name":           {validate.Required(), validate.Length(1, 100)},
+		"email":          {validate.Required(), validate.Email()},
+		"password":       {validate.Required(), validate.Password()},
+	},
+)
+
+var UserErrorType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "UserError",
+		Fields: graphql.Fields{
+			"field": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Path of the offending input field, null when the error is not tied to one.",
+			},
+			"message": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+		},
+	},
+)
+
+// payloadType builds the { result, userErrors } object returned by the
+// input based mutations.
+func payloadType(name string, result graphql.Output) *graphql.Object {
+	return graphql.NewObject(
+		graphql.ObjectConfig{
+			Name: name,
+			Fields: graphql.Fields{
+				"result": &graphql.Field{
+					Type: result,
+				},
+				"userErrors": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(UserErrorType))),
+				},
+			},
+		},
+	)
+}
+
+var (
+	CreateAddressPayloadType = payloadType("CreateAddressPayload", AddressType)
+	CreateProductPayloadType = payloadType("CreateProductPayload", ProductType)
+	SignUpPayloadType        = payloadType("SignUpPayload", UserType)
+)
+
+// withUserErrors wraps the resolver of a payload mutation: its result goes
+// into "result" and problems the caller can fix (failed validation,
+// InvalidArgument or AlreadyExists from upstream) are reported in
+// "userErrors" instead of as top level errors. It must wrap validate.Field.
+func withUserErrors(f *graphql.Field) *graphql.Field {
+	next := f.Resolve
+	f.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+		res, err := next(p)
+		userErrors := []map[string]interface{}{}
+		var invalid *validate.Error
+		switch {
+		case err == nil:
+			return map[string]interface{}{"result": res, "userErrors": userErrors}, nil
+		case errors.As(err, &invalid):
+			for _, v := range invalid.Violations {
+				userErrors = append(userErrors, map[string]interface{}{"field": v.Field, "message": v.Message})
+			}
+		case status.Code(err) == codes.InvalidArgument || status.Code(err) == codes.AlreadyExists:
+			userErrors = append(userErrors, map[string]interface{}{"field": nil, "message": status.Convert(err).Message()})
+		default:
+			return nil, err
+		}
+		return map[string]interface{}{"result": nil, "userErrors": userErrors}, nil
+	}
+	return f
+}
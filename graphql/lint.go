@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// secretFieldName matches output field names that look like credentials.
+var secretFieldName = regexp.MustCompile(`(?i)(password|passwd|secret|hash|salt|credential|token)`)
+
+// LintSchema rejects output types exposing fields named like secrets. It is
+// run at startup so such a field can never be served by accident.
+func LintSchema(schema graphql.Schema) error {
+	var violations []string
+	for name, t := range schema.TypeMap() {
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		var fields graphql.FieldDefinitionMap
+		switch t := t.(type) {
+		case *graphql.Object:
+			fields = t.Fields()
+		case *graphql.Interface:
+			fields = t.Fields()
+		default:
+			continue
+		}
+		for fieldName := range fields {
+			if secretFieldName.MatchString(fieldName) {
+				violations = append(violations, name+"."+fieldName)
+			}
+		}
+	}
+	if len(violations) > 0 {
+		sort.Strings(violations)
+		return fmt.Errorf("schema exposes secret-like output fields: %s", strings.Join(violations, ", "))
+	}
+	return nil
+}
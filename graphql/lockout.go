@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/identity"
+	"github.com/vishnusunil243/api_gateway/lockout"
+)
+
+var LoginKindEnum = graphql.NewEnum(
+	graphql.EnumConfig{
+		Name: "LoginKind",
+		Values: graphql.EnumValueConfigMap{
+			"USER": &graphql.EnumValueConfig{
+				Value: lockout.KindUser,
+			},
+			"ADMIN": &graphql.EnumValueConfig{
+				Value: lockout.KindAdmin,
+			},
+			"SUPER_ADMIN": &graphql.EnumValueConfig{
+				Value: lockout.KindSuperAdmin,
+			},
+		},
+	},
+)
+var LockoutScopeEnum = graphql.NewEnum(
+	graphql.EnumConfig{
+		Name: "LockoutScope",
+		Values: graphql.EnumValueConfigMap{
+			"EMAIL": &graphql.EnumValueConfig{
+				Value: lockout.ScopeEmail,
+			},
+			"IP": &graphql.EnumValueConfig{
+				Value: lockout.ScopeIP,
+			},
+		},
+	},
+)
+
+var LoginLockoutType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "LoginLockout",
+		Fields: graphql.Fields{
+			"kind": &graphql.Field{
+				Type: LoginKindEnum,
+			},
+			"scope": &graphql.Field{
+				Type: LockoutScopeEnum,
+			},
+			"value": &graphql.Field{
+				Type:        graphql.String,
+				Description: "The email or client IP the failures are counted for.",
+			},
+			"failures": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"lastFailure": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"lockedUntil": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "End of the active lock, null when logins are only being delayed.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					st := p.Source.(lockout.State)
+					if !st.Locked(time.Now()) {
+						return nil, nil
+					}
+					return st.LockedUntil, nil
+				},
+			},
+		},
+	},
+)
+
+// canManageLockout lets admins clear user logins while admin and super admin
+// logins can only be cleared by a super admin.
+func canManageLockout(p graphql.ResolveParams, kind string) error {
+	if kind == lockout.KindUser {
+		return nil
+	}
+	if id, ok := identity.FromContext(p.Context); ok && id.HasRole(identity.RoleSuperAdmin) {
+		return nil
+	}
+	return fmt.Errorf("only a super admin can clear %s login lockouts", kind)
+}
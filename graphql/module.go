@@ -0,0 +1,179 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/audit"
+	"github.com/vishnusunil243/api_gateway/cache"
+	"github.com/vishnusunil243/api_gateway/cachecontrol"
+	"github.com/vishnusunil243/api_gateway/idempotency"
+	"github.com/vishnusunil243/api_gateway/lockout"
+	"github.com/vishnusunil243/api_gateway/middleware"
+	"github.com/vishnusunil243/api_gateway/ratelimit"
+	"github.com/vishnusunil243/api_gateway/saga"
+	"github.com/vishnusunil243/proto-files/pb"
+)
+
+// Deps is everything a schema and its handler need. Schemas built from
+// different Deps share no state, so several gateways can run in one
+// process.
+type Deps struct {
+	Products  pb.ProductServiceClient
+	Users     pb.UserServiceClient
+	Carts     pb.CartServiceClient
+	Orders    pb.OrderServiceClient
+	Wishlists pb.WishlistServiceClient
+
+	// Secret signs and checks the session tokens.
+	Secret []byte
+	// Modules is a comma separated list of the modules to serve, all of
+	// them when empty.
+	Modules string
+	// LegacyMutations keeps the flat argument mutations callable.
+	LegacyMutations bool
+	// Signup runs signUp, NewSignupSaga over the clients when nil.
+	Signup *saga.Saga
+	// Cache keeps the results of the public product fields, nothing is
+	// cached when nil.
+	Cache *cache.Cache
+	// Idempotency keeps the keys of OrderAll, idempotency.NewKeys when nil.
+	Idempotency *idempotency.Keys
+	// OrderIds are the status and payment type ids of the order service,
+	// DefaultOrderIds when nil.
+	OrderIds *OrderIds
+	// RateLimiter throttles clients, nothing is limited when nil.
+	RateLimiter *ratelimit.Limiter
+	// Lockouts locks out repeated failed logins, logins are not tracked
+	// when nil.
+	Lockouts *lockout.Tracker
+	// Audit records privileged operations, nothing is recorded when nil.
+	Audit *audit.Auditor
+}
+
+// NewSchema builds the schema of the enabled modules and lints it.
+func NewSchema(deps Deps) (graphql.Schema, error) {
+	auth := middleware.NewAuth(deps.Secret)
+	signup := deps.Signup
+	if signup == nil {
+		signup = NewSignupSaga(deps.Users, deps.Carts, deps.Wishlists)
+	}
+	orderIds := deps.OrderIds
+	if orderIds == nil {
+		orderIds = DefaultOrderIds()
+	}
+	keys := deps.Idempotency
+	if keys == nil {
+		keys = idempotency.NewKeys()
+	}
+	modules, err := Enable(deps.Modules,
+		&Products{Client: deps.Products, Auth: auth, Cache: deps.Cache, Limiter: deps.RateLimiter, Audit: deps.Audit, Legacy: deps.LegacyMutations},
+		&Users{Client: deps.Users, Signup: signup, Auth: auth, Secret: deps.Secret, Limiter: deps.RateLimiter, Lockouts: deps.Lockouts, Audit: deps.Audit, Legacy: deps.LegacyMutations},
+		&Cart{Client: deps.Carts, Auth: auth},
+		&Orders{Client: deps.Orders, Auth: auth, Idempotency: keys, Ids: orderIds, Limiter: deps.RateLimiter, Audit: deps.Audit},
+		&Wishlist{Client: deps.Wishlists, Auth: auth},
+		&Admin{Auth: auth, Limiter: deps.RateLimiter, Lockouts: deps.Lockouts, Audit: deps.Audit},
+	)
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+	schema, err := Build(modules...)
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+	if err := LintSchema(schema); err != nil {
+		return graphql.Schema{}, err
+	}
+	return schema, nil
+}
+
+// Module is one domain of the schema. Register adds the domain's query and
+// mutation fields to the builder, their resolvers use the clients the
+// module was created with.
+type Module interface {
+	Name() string
+	Register(b *Builder)
+}
+
+// Builder collects the root fields registered by the modules.
+type Builder struct {
+	module   string
+	query    graphql.Fields
+	mutation graphql.Fields
+	owners   map[string]string
+	err      error
+}
+
+// Query adds a field to the root query.
+func (b *Builder) Query(name string, f *graphql.Field) {
+	b.add(b.query, "query", name, f)
+}
+
+// Mutation adds a field to the root mutation.
+func (b *Builder) Mutation(name string, f *graphql.Field) {
+	b.add(b.mutation, "mutation", name, f)
+}
+
+func (b *Builder) add(fields graphql.Fields, root, name string, f *graphql.Field) {
+	key := root + " " + name
+	if owner, ok := b.owners[key]; ok {
+		if b.err == nil {
+			b.err = fmt.Errorf("%s field %s is registered by both %s and %s", root, name, owner, b.module)
+		}
+		return
+	}
+	b.owners[key] = b.module
+	fields[name] = f
+}
+
+// Build registers the modules and builds the schema from their fields.
+func Build(modules ...Module) (graphql.Schema, error) {
+	b := &Builder{
+		query:    graphql.Fields{},
+		mutation: graphql.Fields{},
+		owners:   map[string]string{},
+	}
+	for _, m := range modules {
+		b.module = m.Name()
+		m.Register(b)
+	}
+	if b.err != nil {
+		return graphql.Schema{}, b.err
+	}
+	config := graphql.SchemaConfig{
+		Query: cachecontrol.Object(graphql.ObjectConfig{
+			Name:   "RootQuery",
+			Fields: b.query,
+		}),
+		Directives: append(graphql.SpecifiedDirectives, cachecontrol.Directive),
+	}
+	if len(b.mutation) > 0 {
+		config.Mutation = cachecontrol.Object(graphql.ObjectConfig{
+			Name:   "Mutation",
+			Fields: b.mutation,
+		})
+	}
+	return graphql.NewSchema(config)
+}
+
+// Enable picks the modules named in the comma separated list, or all of
+// them when the list is empty.
+func Enable(names string, modules ...Module) ([]Module, error) {
+	if strings.TrimSpace(names) == "" {
+		return modules, nil
+	}
+	byName := map[string]Module{}
+	for _, m := range modules {
+		byName[m.Name()] = m
+	}
+	var enabled []Module
+	for _, name := range strings.Split(names, ",") {
+		m, ok := byName[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown schema module %q", strings.TrimSpace(name))
+		}
+		enabled = append(enabled, m)
+	}
+	return enabled, nil
+}
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+// moduleFields has one query field served by each module.
+var moduleFields = map[string]string{
+	"products": "products",
+	"users":    "GetUser",
+	"cart":     "GetAllCartItems",
+	"orders":   "GetAllOrdersUser",
+	"wishlist": "GetAllWishlist",
+	"admin":    "auditLog",
+}
+
+func TestNewSchemaModules(t *testing.T) {
+	for _, modules := range []string{"", "products", "users", "cart", "orders", "wishlist", "admin", "products, orders"} {
+		schema, err := NewSchema(Deps{Modules: modules})
+		if err != nil {
+			t.Errorf("modules %q: %s", modules, err.Error())
+			continue
+		}
+		fields := schema.QueryType().Fields()
+		for module, field := range moduleFields {
+			want := modules == "" || strings.Contains(modules, module)
+			if _, served := fields[field]; served != want {
+				t.Errorf("modules %q: %s served = %v, want %v", modules, field, served, want)
+			}
+		}
+	}
+}
+
+func TestNewSchemaUnknownModule(t *testing.T) {
+	_, err := NewSchema(Deps{Modules: "products,reviews"})
+	if err == nil || !strings.Contains(err.Error(), `unknown schema module "reviews"`) {
+		t.Errorf("got error %v, want unknown schema module", err)
+	}
+}
+
+type fieldModule struct {
+	name  string
+	field string
+}
+
+func (m fieldModule) Name() string {
+	return m.name
+}
+
+func (m fieldModule) Register(b *Builder) {
+	b.Query(m.field, &graphql.Field{Type: graphql.String})
+}
+
+func TestBuildDuplicateField(t *testing.T) {
+	_, err := Build(fieldModule{"a", "shared"}, fieldModule{"b", "shared"})
+	if err == nil || err.Error() != "query field shared is registered by both a and b" {
+		t.Errorf("got error %v, want the duplicate registration", err)
+	}
+	if _, err := Build(fieldModule{"a", "one"}, fieldModule{"b", "two"}); err != nil {
+		t.Errorf("distinct fields: %s", err.Error())
+	}
+}
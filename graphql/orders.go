@@ -0,0 +1,219 @@
+package graph
+
+import (
+	"io"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/audit"
+	"github.com/vishnusunil243/api_gateway/idempotency"
+	"github.com/vishnusunil243/api_gateway/middleware"
+	"github.com/vishnusunil243/api_gateway/ratelimit"
+	"github.com/vishnusunil243/api_gateway/validate"
+	"github.com/vishnusunil243/proto-files/pb"
+)
+
+// newOrderType builds the Order type of a schema, whose status and payment
+// type are resolved from the ids of its order service.
+func newOrderType(ids *OrderIds) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Order",
+		Fields: graphql.Fields{
+			"orderId": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"orderItems": &graphql.Field{
+				Type: graphql.NewList(ProductType),
+			},
+			"addressId": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"orderStatusId": &graphql.Field{
+				Type:              graphql.Int,
+				DeprecationReason: "Use orderStatus.",
+			},
+			"orderStatus": &graphql.Field{
+				Type: OrderStatusEnum,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if order, ok := p.Source.(*pb.GetAllOrderResponse); ok {
+						return nullable(ids.name(ids.Statuses, order.OrderStatusId)), nil
+					}
+					return nil, nil
+				},
+			},
+			"paymentTypeId": &graphql.Field{
+				Type:              graphql.Int,
+				DeprecationReason: "Use paymentType.",
+			},
+			"paymentType": &graphql.Field{
+				Type: PaymentTypeEnum,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if order, ok := p.Source.(*pb.GetAllOrderResponse); ok {
+						return nullable(ids.name(ids.PaymentTypes, order.PaymentTypeId)), nil
+					}
+					return nil, nil
+				},
+			},
+			"total": &graphql.Field{
+				Type: graphql.Float,
+			},
+		},
+	})
+}
+
+// nullable resolves an id no enum value has to null.
+func nullable(name string) interface{} {
+	if name == "" {
+		return nil
+	}
+	return name
+}
+
+// Orders serves placing, listing and updating orders.
+type Orders struct {
+	Client      pb.OrderServiceClient
+	Auth        *middleware.Auth
+	Idempotency *idempotency.Keys
+	Ids         *OrderIds
+	Limiter     *ratelimit.Limiter
+	Audit       *audit.Auditor
+}
+
+func (m *Orders) Name() string {
+	return "orders"
+}
+
+func (m *Orders) Register(b *Builder) {
+	orderType := newOrderType(m.Ids)
+	b.Query("GetAllOrdersUser", &graphql.Field{
+		Type: graphql.NewList(orderType),
+		Resolve: m.Auth.UserMiddleware(m.Limiter.Operation("GetAllOrdersUser", func(p graphql.ResolveParams) (interface{}, error) {
+			userIdVal := p.Context.Value("userId").(uint)
+			orders, err := m.Client.GetAllOrdersUser(p.Context, &pb.OrderRequest{
+				UserId: uint32(userIdVal),
+			})
+			if err != nil {
+				return nil, err
+			}
+			var AllOrders []*pb.GetAllOrderResponse
+			for {
+				order, err := orders.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				AllOrders = append(AllOrders, order)
+			}
+			return AllOrders, nil
+		})),
+	})
+	b.Query("GetAllOrders", &graphql.Field{
+		Type: graphql.NewList(orderType),
+		Resolve: m.Auth.AdminMiddleware(m.Limiter.Operation("GetAllOrders", func(p graphql.ResolveParams) (interface{}, error) {
+			orders, err := m.Client.GetAllOrders(p.Context, &pb.NoParam{})
+			if err != nil {
+				return nil, err
+			}
+			var res []*pb.GetAllOrderResponse
+			for {
+				order, err := orders.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, order)
+			}
+			return res, nil
+		})),
+	})
+	b.Query("GetOrder", validate.Field(&graphql.Field{
+		Type: orderType,
+		Args: graphql.FieldConfigArgument{
+			"orderId": validate.Arg(graphql.Int, validate.Required(), validate.Min(1)),
+		},
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			return m.Client.GetOrder(p.Context, &pb.OrderResponse{
+				OrderId: uint32(p.Args["orderId"].(int)),
+			})
+		}),
+	}))
+
+	b.Mutation("OrderAll", validate.Field(&graphql.Field{
+		Type: orderType,
+		Args: graphql.FieldConfigArgument{
+			"idempotencyKey": validate.Arg(graphql.String, validate.Length(1, idempotency.MaxKeyLength)),
+		},
+		Resolve: m.Auth.UserMiddleware(m.Limiter.Operation("OrderAll", m.Idempotency.Mutation("OrderAll", func() interface{} { return &pb.OrderResponse{} }, func(p graphql.ResolveParams) (interface{}, error) {
+			userIdVal := p.Context.Value("userId").(uint)
+			order, err := m.Client.OrderAll(p.Context, &pb.OrderRequest{
+				UserId: uint32(userIdVal),
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return order, nil
+		}))),
+	}))
+	b.Mutation("UserCancelOrder", validate.Field(&graphql.Field{
+		Type: orderType,
+		Args: graphql.FieldConfigArgument{
+			"orderId": validate.Arg(graphql.NewNonNull(graphql.Int), validate.Min(1)),
+		},
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			orderId := uint32(p.Args["orderId"].(int))
+			order, err := m.Client.GetOrder(p.Context, &pb.OrderResponse{OrderId: orderId})
+			if err != nil {
+				return nil, err
+			}
+			if err := m.Ids.checkOrderTransition("orderId", order.OrderStatusId, m.Ids.Statuses["CANCELLED"]); err != nil {
+				return nil, err
+			}
+			return m.Client.UserCancelOrder(p.Context, &pb.OrderResponse{
+				OrderId: orderId,
+			})
+		}),
+	}))
+	b.Mutation("ChangeOrderStatus", validate.Field(&graphql.Field{
+		Type: orderType,
+		Args: graphql.FieldConfigArgument{
+			"orderId": validate.Arg(graphql.NewNonNull(graphql.Int), validate.Min(1)),
+			"status": &graphql.ArgumentConfig{
+				Type: OrderStatusEnum,
+			},
+			"statusId": &graphql.ArgumentConfig{
+				Type:        graphql.Int,
+				Description: "Deprecated: use status.",
+			},
+		},
+		Resolve: m.Auth.AdminMiddleware(m.Audit.Privileged("ChangeOrderStatus", func(p graphql.ResolveParams) (interface{}, error) {
+			orderId := uint32(p.Args["orderId"].(int))
+			var statusId uint32
+			if status, ok := p.Args["status"].(string); ok {
+				statusId = m.Ids.Statuses[status]
+			} else if id, ok := p.Args["statusId"].(int); ok && id > 0 {
+				statusId = uint32(id)
+			} else {
+				return nil, &validate.Error{Violations: []validate.Violation{{Field: "status", Message: "is required"}}}
+			}
+			order, err := m.Client.GetOrder(p.Context, &pb.OrderResponse{OrderId: orderId})
+			if err != nil {
+				return nil, err
+			}
+			if err := m.Ids.checkOrderTransition("status", order.OrderStatusId, statusId); err != nil {
+				return nil, err
+			}
+			if _, err := m.Client.ChangeOrderStatus(p.Context, &pb.ChangeOrderStatusRequest{
+				OrderId:  orderId,
+				StatusId: statusId,
+			}); err != nil {
+				return nil, err
+			}
+			order.OrderStatusId = statusId
+			return order, nil
+		})),
+	}))
+}
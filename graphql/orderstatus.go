@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/validate"
+)
+
+// OrderIds maps the OrderStatus and PaymentType values to the ids the order
+// service stores. The proto does not define them, so they must match the
+// deployed order service; DefaultOrderIds can be overridden with
+// ParseOrderIds.
+type OrderIds struct {
+	Statuses     map[string]uint32
+	PaymentTypes map[string]uint32
+}
+
+func DefaultOrderIds() *OrderIds {
+	return &OrderIds{
+		Statuses: map[string]uint32{
+			"PENDING":    1,
+			"PROCESSING": 2,
+			"SHIPPED":    3,
+			"DELIVERED":  4,
+			"CANCELLED":  5,
+			"RETURNED":   6,
+		},
+		PaymentTypes: map[string]uint32{
+			"CASH_ON_DELIVERY": 1,
+			"ONLINE":           2,
+		},
+	}
+}
+
+// ParseOrderIds overrides the defaults with lists such as
+// "PENDING=1,CANCELLED=5", either of which may be empty. Two values of an
+// enum may not end up with the same id.
+func ParseOrderIds(statuses, paymentTypes string) (*OrderIds, error) {
+	ids := DefaultOrderIds()
+	for _, spec := range []struct {
+		list string
+		enum *graphql.Enum
+		ids  map[string]uint32
+	}{
+		{statuses, OrderStatusEnum, ids.Statuses},
+		{paymentTypes, PaymentTypeEnum, ids.PaymentTypes},
+	} {
+		if strings.TrimSpace(spec.list) == "" {
+			continue
+		}
+		for _, pair := range strings.Split(spec.list, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			if _, ok := spec.ids[name]; !ok {
+				return nil, fmt.Errorf("%s has no value %q", spec.enum.Name(), name)
+			}
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid id of %s %s: %q", spec.enum.Name(), name, value)
+			}
+			spec.ids[name] = uint32(id)
+		}
+		// ids are mapped back to values, each must name only one
+		names := map[uint32]string{}
+		for name, id := range spec.ids {
+			if other, ok := names[id]; ok {
+				if other > name {
+					other, name = name, other
+				}
+				return nil, fmt.Errorf("%s values %s and %s have the same id %d", spec.enum.Name(), other, name, id)
+			}
+			names[id] = name
+		}
+	}
+	return ids, nil
+}
+
+// name returns the value of an id, or "" when no value has it.
+func (ids *OrderIds) name(values map[string]uint32, id uint32) string {
+	for name, v := range values {
+		if v == id {
+			return name
+		}
+	}
+	return ""
+}
+
+var OrderStatusEnum = graphql.NewEnum(
+	graphql.EnumConfig{
+		Name: "OrderStatus",
+		Values: graphql.EnumValueConfigMap{
+			"PENDING": &graphql.EnumValueConfig{
+				Value:       "PENDING",
+				Description: "Placed and waiting to be processed.",
+			},
+			"PROCESSING": &graphql.EnumValueConfig{
+				Value:       "PROCESSING",
+				Description: "Being packed.",
+			},
+			"SHIPPED": &graphql.EnumValueConfig{
+				Value: "SHIPPED",
+			},
+			"DELIVERED": &graphql.EnumValueConfig{
+				Value: "DELIVERED",
+			},
+			"CANCELLED": &graphql.EnumValueConfig{
+				Value: "CANCELLED",
+			},
+			"RETURNED": &graphql.EnumValueConfig{
+				Value: "RETURNED",
+			},
+		},
+	},
+)
+var PaymentTypeEnum = graphql.NewEnum(
+	graphql.EnumConfig{
+		Name: "PaymentType",
+		Values: graphql.EnumValueConfigMap{
+			"CASH_ON_DELIVERY": &graphql.EnumValueConfig{
+				Value: "CASH_ON_DELIVERY",
+			},
+			"ONLINE": &graphql.EnumValueConfig{
+				Value: "ONLINE",
+			},
+		},
+	},
+)
+
+// orderTransitions lists the statuses an order may move to from each status.
+// Cancelled and returned orders are final.
+var orderTransitions = map[string][]string{
+	"PENDING":    {"PROCESSING", "CANCELLED"},
+	"PROCESSING": {"SHIPPED", "CANCELLED"},
+	"SHIPPED":    {"DELIVERED"},
+	"DELIVERED":  {"RETURNED"},
+}
+
+// checkOrderTransition returns a BAD_USER_INPUT error reported against
+// field when an order cannot move from one status to another. Ids with no
+// OrderStatus value are left for the order service to check.
+func (ids *OrderIds) checkOrderTransition(field string, fromId, toId uint32) error {
+	from, to := ids.name(ids.Statuses, fromId), ids.name(ids.Statuses, toId)
+	if from == "" || to == "" {
+		return nil
+	}
+	for _, allowed := range orderTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &validate.Error{Violations: []validate.Violation{{
+		Field:   field,
+		Message: fmt.Sprintf("cannot move an order from %s to %s", from, to),
+	}}}
+}
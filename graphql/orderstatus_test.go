@@ -0,0 +1,28 @@
+package graph_test
+
+import (
+	"testing"
+
+	graph "github.com/vishnusunil243/api_gateway/graphql"
+)
+
+func TestParseOrderIds(t *testing.T) {
+	for _, tc := range []struct {
+		statuses, paymentTypes string
+		ok                     bool
+	}{
+		{"", "", true},
+		{"PENDING=10,CANCELLED=50", "ONLINE=7", true},
+		{"PENDING=2,PROCESSING=1", "", true},
+		{"PENDING=2", "", false},
+		{"SHIPPED=9,DELIVERED=9", "", false},
+		{"", "ONLINE=1", false},
+		{"LOST=7", "", false},
+		{"PENDING=x", "", false},
+	} {
+		_, err := graph.ParseOrderIds(tc.statuses, tc.paymentTypes)
+		if ok := err == nil; ok != tc.ok {
+			t.Errorf("ParseOrderIds(%q, %q): got %v", tc.statuses, tc.paymentTypes, err)
+		}
+	}
+}
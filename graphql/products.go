@@ -0,0 +1,145 @@
+package graph
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/audit"
+	"github.com/vishnusunil243/api_gateway/cache"
+	"github.com/vishnusunil243/api_gateway/cachecontrol"
+	"github.com/vishnusunil243/api_gateway/middleware"
+	"github.com/vishnusunil243/api_gateway/ratelimit"
+	"github.com/vishnusunil243/api_gateway/validate"
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var numericID = regexp.MustCompile(`^[0-9]+$`)
+
+var ProductType = cachecontrol.Object(
+	graphql.ObjectConfig{
+		Name: "product",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+			"total": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"quantity": cachecontrol.Field(10, &graphql.Field{
+				Type: graphql.Int,
+			}),
+			"price": &graphql.Field{
+				Type: graphql.Int,
+			},
+		},
+	},
+)
+
+// Products serves the product catalogue.
+type Products struct {
+	Client  pb.ProductServiceClient
+	Auth    *middleware.Auth
+	Cache   *cache.Cache
+	Limiter *ratelimit.Limiter
+	Audit   *audit.Auditor
+	Legacy  bool
+}
+
+func (m *Products) Name() string {
+	return "products"
+}
+
+func (m *Products) Register(b *Builder) {
+	b.Query("products", cachecontrol.Field(60, &graphql.Field{
+		Type: graphql.NewList(ProductType),
+		Resolve: m.Cache.Field("products", func() interface{} { return &[]*pb.AddProductResponse{} }, m.Limiter.Operation("products", func(p graphql.ResolveParams) (interface{}, error) {
+			products, err := m.Client.GetAllProducts(p.Context, &emptypb.Empty{})
+			if err != nil {
+				return nil, err
+			}
+			var res []*pb.AddProductResponse
+			for {
+				prod, err := products.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, prod)
+			}
+			return res, nil
+		})),
+	}))
+	b.Query("product", cachecontrol.Field(60, validate.Field(&graphql.Field{
+		Type: ProductType,
+		Args: graphql.FieldConfigArgument{
+			"id": validate.Arg(graphql.NewNonNull(graphql.Int), validate.Min(1)),
+		},
+		Resolve: m.Cache.Field("product", func() interface{} { return &pb.AddProductResponse{} }, func(p graphql.ResolveParams) (interface{}, error) {
+			return m.Client.GetProduct(p.Context, &pb.GetProductById{
+				Id: int32(p.Args["id"].(int)),
+			})
+		}),
+	})))
+
+	b.Mutation("AddProduct", validate.Field(&graphql.Field{
+		Type:              ProductType,
+		DeprecationReason: "Use createProduct.",
+		Args: graphql.FieldConfigArgument{
+			"name":     validate.Arg(graphql.NewNonNull(graphql.String), validate.Required(), validate.Length(1, 200)),
+			"price":    validate.Arg(graphql.NewNonNull(graphql.Int), validate.Range(1, 10000000)),
+			"quantity": validate.Arg(graphql.NewNonNull(graphql.Int), validate.Range(0, 100000)),
+		},
+		Resolve: legacyMutation(m.Legacy, "createProduct", m.Auth.AdminMiddleware(m.Audit.Privileged("AddProduct", m.Cache.Invalidating(func(p graphql.ResolveParams) (interface{}, error) {
+			return m.createProduct(p, p.Args)
+		}, "products", "product")))),
+	}))
+	b.Mutation("createProduct", withUserErrors(validate.Field(&graphql.Field{
+		Type: CreateProductPayloadType,
+		Args: graphql.FieldConfigArgument{
+			"input": &graphql.ArgumentConfig{
+				Type: graphql.NewNonNull(ProductInputType),
+			},
+		},
+		Resolve: m.Auth.AdminMiddleware(m.Audit.Privileged("createProduct", m.Cache.Invalidating(func(p graphql.ResolveParams) (interface{}, error) {
+			return m.createProduct(p, p.Args["input"].(map[string]interface{}))
+		}, "products", "product"))),
+	})))
+	b.Mutation("UpdateQuantity", validate.Field(&graphql.Field{
+		Type: ProductType,
+		Args: graphql.FieldConfigArgument{
+			"id":       validate.Arg(graphql.NewNonNull(graphql.ID), validate.Match(numericID, "must be a numeric id")),
+			"quantity": validate.Arg(graphql.NewNonNull(graphql.Int), validate.Range(1, 100000)),
+			"increase": &graphql.ArgumentConfig{
+				Type: graphql.NewNonNull(graphql.Boolean),
+			},
+		},
+		Resolve: m.Auth.AdminMiddleware(m.Audit.Privileged("UpdateQuantity", m.Cache.Invalidating(func(p graphql.ResolveParams) (interface{}, error) {
+			id, _ := strconv.Atoi(p.Args["id"].(string))
+			return m.Client.UpdateQuantity(p.Context, &pb.UpdateQuantityRequest{
+				Id:       uint32(id),
+				Quantity: int32(p.Args["quantity"].(int)),
+				Increase: p.Args["increase"].(bool),
+			})
+		}, "products", "product"))),
+	}))
+}
+
+func (m *Products) createProduct(p graphql.ResolveParams, input map[string]interface{}) (interface{}, error) {
+	products, err := m.Client.AddProduct(p.Context, &pb.AddProductRequest{
+		Name:     input["name"].(string),
+		Price:    int32(input["price"].(int)),
+		Quantity: int32(input["quantity"].(int)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
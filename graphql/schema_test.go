@@ -0,0 +1,41 @@
+package graph_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	graph "github.com/vishnusunil243/api_gateway/graphql"
+	"github.com/vishnusunil243/api_gateway/sdl"
+)
+
+// TestSchemaSnapshot fails when the schema no longer matches the checked-in
+// schema.graphql. Update it with go run ./cmd/schema -write schema.graphql
+// once the changes listed are intended.
+func TestSchemaSnapshot(t *testing.T) {
+	schema, err := graph.NewSchema(graph.Deps{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := os.ReadFile("../schema.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := sdl.Print(&schema)
+	if string(snapshot) == current {
+		return
+	}
+	changes, err := sdl.Diff(string(snapshot), current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "only additions, descriptions or formatting changed")
+	}
+	t.Errorf("schema.graphql is out of date (%d breaking), run go run ./cmd/schema -write schema.graphql:\n%s",
+		len(sdl.Breaking(changes)), strings.Join(lines, "\n"))
+}
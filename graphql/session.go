@@ -0,0 +1,176 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/authorize"
+	"github.com/vishnusunil243/api_gateway/helper"
+	"github.com/vishnusunil243/api_gateway/identity"
+	"github.com/vishnusunil243/api_gateway/lockout"
+	"github.com/vishnusunil243/api_gateway/validate"
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/grpc"
+)
+
+const sessionCookie = "jwtToken"
+
+// secureCookies reports whether the request came in over TLS, in which case
+// the session cookie must not be sent back over plain HTTP.
+func secureCookies(p graphql.ResolveParams) bool {
+	r, ok := p.Context.Value("request").(*http.Request)
+	return ok && r.TLS != nil
+}
+
+var AuthPayloadType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "AuthPayload",
+		Fields: graphql.Fields{
+			"user": &graphql.Field{
+				Type: UserType,
+			},
+			"expiresAt": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "When the session cookie set by the login stops being accepted.",
+			},
+			"roles": &graphql.Field{
+				Type: graphql.NewList(graphql.String),
+			},
+		},
+	},
+)
+
+// loginKind describes one of the login operations, call is the user service
+// method checking the credentials.
+type loginKind struct {
+	operation string
+	mutation  string
+	lockout   string
+	call      func(c pb.UserServiceClient, ctx context.Context, in *pb.UserLoginRequest, opts ...grpc.CallOption) (*pb.UserSignupResponse, error)
+	admin     bool
+	superuser bool
+}
+
+var (
+	userLogin = loginKind{
+		operation: "UserLogin",
+		mutation:  "userLogin",
+		lockout:   lockout.KindUser,
+		call:      pb.UserServiceClient.UserLogin,
+	}
+	adminLogin = loginKind{
+		operation: "AdminLogin",
+		mutation:  "adminLogin",
+		lockout:   lockout.KindAdmin,
+		call:      pb.UserServiceClient.AdminLogin,
+		admin:     true,
+	}
+	superAdminLogin = loginKind{
+		operation: "SuperAdminLogin",
+		mutation:  "superAdminLogin",
+		lockout:   lockout.KindSuperAdmin,
+		call:      pb.UserServiceClient.SuperAdminLogin,
+		admin:     true,
+		superuser: true,
+	}
+)
+
+// issueSession signs a token for user, sets it as the session cookie and
+// describes the session.
+func (m *Users) issueSession(p graphql.ResolveParams, user *pb.UserSignupResponse, admin, superuser bool) (helper.AuthPayload, error) {
+	token, expiresAt, err := authorize.IssueJwt(uint(user.Id), admin, superuser, m.Secret)
+	if err != nil {
+		return helper.AuthPayload{}, err
+	}
+	setSessionCookie(p, token, int(time.Until(expiresAt).Seconds()))
+	roles := []string{identity.RoleUser}
+	if admin {
+		roles = append(roles, identity.RoleAdmin)
+	}
+	if superuser {
+		roles = append(roles, identity.RoleSuperAdmin)
+	}
+	return helper.AuthPayload{
+		User:      helper.NewUserProfile(user),
+		ExpiresAt: expiresAt,
+		Roles:     roles,
+	}, nil
+}
+
+func endSession(p graphql.ResolveParams) {
+	setSessionCookie(p, "", -1)
+}
+
+func setSessionCookie(p graphql.ResolveParams, value string, maxAge int) {
+	w := p.Context.Value("httpResponseWriter").(http.ResponseWriter)
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    value,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secureCookies(p),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// methodError rejects the deprecated session queries sent with GET, which
+// a cross-site link could otherwise use to log a browser in or out.
+type methodError struct {
+	field string
+}
+
+func (e *methodError) Error() string {
+	return e.field + " changes the session and must be sent with POST"
+}
+
+func (e *methodError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": "METHOD_NOT_ALLOWED"}
+}
+
+// postOnly fails next when the request is a GET.
+func postOnly(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if r, ok := p.Context.Value("request").(*http.Request); ok && r.Method == http.MethodGet {
+			return nil, &methodError{field: p.Info.FieldName}
+		}
+		return next(p)
+	}
+}
+
+// loginField builds a login operation. The mutations return the AuthPayload
+// while the deprecated query aliases keep returning just the user. Both
+// share the rate limit and lockout of the operation.
+func (m *Users) loginField(kind loginKind, alias bool) *graphql.Field {
+	f := &graphql.Field{
+		Type: AuthPayloadType,
+		Args: graphql.FieldConfigArgument{
+			"email":    validate.Arg(graphql.NewNonNull(graphql.String), validate.Required(), validate.Email()),
+			"password": validate.Arg(graphql.NewNonNull(graphql.String), validate.Required(), validate.Length(1, 72)),
+		},
+		Resolve: m.Limiter.Operation(kind.operation, m.Lockouts.Login(kind.lockout, func(p graphql.ResolveParams) (interface{}, error) {
+			user, err := kind.call(m.Client, p.Context, &pb.UserLoginRequest{
+				Email:    p.Args["email"].(string),
+				Password: p.Args["password"].(string),
+			})
+			if err != nil {
+				return nil, err
+			}
+			session, err := m.issueSession(p, user, kind.admin, kind.superuser)
+			if err != nil {
+				return nil, err
+			}
+			if alias {
+				return session.User, nil
+			}
+			return session, nil
+		})),
+	}
+	if alias {
+		f.Type = UserType
+		f.DeprecationReason = "Logging in changes state, use the " + kind.mutation + " mutation."
+		f.Resolve = postOnly(f.Resolve)
+	}
+	return validate.Field(f)
+}
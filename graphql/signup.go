@@ -0,0 +1,149 @@
+package graph
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/helper"
+	"github.com/vishnusunil243/api_gateway/idempotency"
+	"github.com/vishnusunil243/api_gateway/saga"
+	"github.com/vishnusunil243/proto-files/pb"
+)
+
+// NewSignupSaga creates the user, their cart and their wishlist. The user
+// service has no way to delete a user, so a failed cart or wishlist step is
+// never compensated: the run stays incomplete and is finished by signing up
+// again with the same idempotency key or by Resume.
+func NewSignupSaga(users pb.UserServiceClient, carts pb.CartServiceClient, wishlists pb.WishlistServiceClient) *saga.Saga {
+	return &saga.Saga{
+		Name:    "signup",
+		Store:   saga.NewMemoryStore(24 * time.Hour),
+		Retries: 2,
+		Backoff: 200 * time.Millisecond,
+		Steps: []saga.Step{
+			{
+				Name: "createUser",
+				Do: func(ctx context.Context, data map[string]string) error {
+					res, err := users.UserSignup(ctx, &pb.UserSignupRequest{
+						Name:     data["name"],
+						Email:    data["email"],
+						Password: data["password"],
+					})
+					if err != nil {
+						return err
+					}
+					// the password is not needed by later steps, keep it out of the store
+					delete(data, "password")
+					data["userId"] = strconv.FormatUint(uint64(res.Id), 10)
+					data["name"] = res.Name
+					data["email"] = res.Email
+					return nil
+				},
+			},
+			{
+				Name: "createCart",
+				Do: func(ctx context.Context, data map[string]string) error {
+					userId, err := sagaUserId(data)
+					if err != nil {
+						return err
+					}
+					cart, err := carts.CreateCart(ctx, &pb.UserCartCreate{UserId: userId})
+					if err != nil {
+						return err
+					}
+					if cart.UserId == 0 {
+						return fmt.Errorf("error creating cart")
+					}
+					return nil
+				},
+			},
+			{
+				Name: "createWishlist",
+				Do: func(ctx context.Context, data map[string]string) error {
+					userId, err := sagaUserId(data)
+					if err != nil {
+						return err
+					}
+					_, err = wishlists.CreateWishlist(ctx, &pb.CreateWishlistRequest{UserId: userId})
+					return err
+				},
+			},
+		},
+	}
+}
+
+func sagaUserId(data map[string]string) (uint32, error) {
+	id, err := strconv.ParseUint(data["userId"], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("signup has no user id")
+	}
+	return uint32(id), nil
+}
+
+var errSignupKeyReused = &idempotency.Error{Code: "IDEMPOTENCY_KEY_REUSED", Message: "idempotency key was already used with different arguments"}
+
+// signupFingerprint identifies the input a signup idempotency key was first
+// used with. The key salts the hash since the password is part of it.
+func signupFingerprint(key string, input map[string]interface{}) string {
+	h := sha256.New()
+	for _, v := range []string{key, input["name"].(string), input["email"].(string), input["password"].(string)} {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (m *Users) signUp(p graphql.ResolveParams, input map[string]interface{}) (interface{}, error) {
+	// only a key sent by the client dedupes, a signup without one is its
+	// own run so that signing up with a taken email is still reported
+	key, _ := input["idempotencyKey"].(string)
+	if key == "" {
+		random := make([]byte, 16)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		key = "anonymous:" + hex.EncodeToString(random)
+	}
+	key = "signup:" + key
+	fingerprint := signupFingerprint(key, input)
+	if rec, ok, err := m.Signup.Store.Load(p.Context, key); err != nil {
+		return nil, err
+	} else if ok && rec.Status != saga.StatusCompensated && rec.Data["input"] != fingerprint {
+		return nil, errSignupKeyReused
+	}
+	rec, err := m.Signup.Run(p.Context, key, map[string]string{
+		"name":     input["name"].(string),
+		"email":    input["email"].(string),
+		"password": input["password"].(string),
+		"input":    fingerprint,
+	})
+	var failed *saga.Error
+	if errors.As(err, &failed) && !failed.Resumable {
+		// nothing was left behind, report the upstream error as is so that
+		// e.g. an existing email still ends up in userErrors
+		return nil, failed.Err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rec.Data["input"] != fingerprint {
+		// another run of the key with a different input won the race
+		return nil, errSignupKeyReused
+	}
+	userId, err := sagaUserId(rec.Data)
+	if err != nil {
+		return nil, err
+	}
+	return helper.UserProfile{
+		Id:    userId,
+		Name:  rec.Data["name"],
+		Email: rec.Data["email"],
+	}, nil
+}
@@ -0,0 +1,308 @@
+package graph
+
+import (
+	"io"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/audit"
+	"github.com/vishnusunil243/api_gateway/helper"
+	"github.com/vishnusunil243/api_gateway/identity"
+	"github.com/vishnusunil243/api_gateway/lockout"
+	"github.com/vishnusunil243/api_gateway/middleware"
+	"github.com/vishnusunil243/api_gateway/ratelimit"
+	"github.com/vishnusunil243/api_gateway/saga"
+	"github.com/vishnusunil243/api_gateway/validate"
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var UserType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "user",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+			"email": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	},
+)
+
+var AdminUserType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "AdminUser",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+			"email": &graphql.Field{
+				Type: graphql.String,
+			},
+			"role": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	},
+)
+var AddressType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "address",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"city": &graphql.Field{
+				Type: graphql.String,
+			},
+			"district": &graphql.Field{
+				Type: graphql.String,
+			},
+			"state": &graphql.Field{
+				Type: graphql.String,
+			},
+			"road": &graphql.Field{
+				Type: graphql.String,
+			},
+			"userId": &graphql.Field{
+				Type: graphql.Int,
+			},
+		},
+	},
+)
+
+// Users serves signups, sessions, addresses and the user and admin
+// accounts. Signup runs the saga built by NewSignupSaga and Secret signs the
+// session tokens Auth checks.
+type Users struct {
+	Client   pb.UserServiceClient
+	Signup   *saga.Saga
+	Auth     *middleware.Auth
+	Secret   []byte
+	Limiter  *ratelimit.Limiter
+	Lockouts *lockout.Tracker
+	Audit    *audit.Auditor
+	Legacy   bool
+}
+
+func (m *Users) Name() string {
+	return "users"
+}
+
+func (m *Users) Register(b *Builder) {
+	b.Query("UserLogin", m.loginField(userLogin, true))
+	b.Query("AdminLogin", m.loginField(adminLogin, true))
+	b.Query("SuperAdminLogin", m.loginField(superAdminLogin, true))
+	b.Query("Logout", &graphql.Field{
+		Type:              UserType,
+		DeprecationReason: "Logging out changes state, use the logout mutation.",
+		Resolve: postOnly(m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			userIdVal := p.Context.Value("userId").(uint)
+			endSession(p)
+			return helper.UserProfile{Id: uint32(userIdVal)}, nil
+		},
+		)),
+	})
+	b.Query("GetAllAdmins", &graphql.Field{
+		Type: graphql.NewList(AdminUserType),
+		Resolve: m.Auth.SuperAdminMiddleware(m.Limiter.Operation("GetAllAdmins", func(p graphql.ResolveParams) (interface{}, error) {
+			admins, err := m.Client.GetAllAdmins(p.Context, &emptypb.Empty{})
+			if err != nil {
+				return nil, err
+			}
+			var res []*pb.UserSignupResponse
+			for {
+				admin, err := admins.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, admin)
+			}
+			return helper.NewAdminUserViews(res, identity.RoleAdmin), nil
+		})),
+	})
+	b.Query("GetAllUsers", &graphql.Field{
+		Type: graphql.NewList(AdminUserType),
+		Resolve: m.Auth.AdminMiddleware(m.Limiter.Operation("GetAllUsers", func(p graphql.ResolveParams) (interface{}, error) {
+			users, err := m.Client.GetAllUsers(p.Context, &emptypb.Empty{})
+			if err != nil {
+				return nil, err
+			}
+			var res []*pb.UserSignupResponse
+			for {
+				user, err := users.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, user)
+
+			}
+			return helper.NewAdminUserViews(res, identity.RoleUser), nil
+		})),
+	})
+	b.Query("GetUser", validate.Field(&graphql.Field{
+		Type: AdminUserType,
+		Args: graphql.FieldConfigArgument{
+			"id": validate.Arg(graphql.NewNonNull(graphql.Int), validate.Min(1)),
+		},
+		Resolve: m.Auth.AdminMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			user, err := m.Client.GetUser(p.Context, &pb.GetUserById{
+				Id: uint32(p.Args["id"].(int)),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return helper.NewAdminUserView(user, identity.RoleUser), nil
+		}),
+	}))
+	b.Query("GetAdmin", validate.Field(&graphql.Field{
+		Type: AdminUserType,
+		Args: graphql.FieldConfigArgument{
+			"id": validate.Arg(graphql.NewNonNull(graphql.Int), validate.Min(1)),
+		},
+		Resolve: m.Auth.SuperAdminMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			admin, err := m.Client.GetAdmin(p.Context, &pb.GetUserById{
+				Id: uint32(p.Args["id"].(int)),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return helper.NewAdminUserView(admin, identity.RoleAdmin), nil
+		}),
+	}))
+	b.Query("GetAddress", &graphql.Field{
+		Type: AddressType,
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			userIdVal := p.Context.Value("userId").(uint)
+			res, err := m.Client.GetAddress(p.Context, &pb.GetUserById{
+				Id: uint32(userIdVal),
+			})
+			if err != nil {
+				return nil, err
+			}
+			address := helper.AddressResponse{
+				Id:       res.Id,
+				UserID:   res.UserId,
+				City:     res.City,
+				District: res.District,
+				State:    res.State,
+				Road:     res.Road,
+			}
+			return address, nil
+		}),
+	})
+
+	b.Mutation("UserSignup", validate.Field(&graphql.Field{
+		Type:              UserType,
+		DeprecationReason: "Use signUp.",
+		Args: graphql.FieldConfigArgument{
+			"name":     validate.Arg(graphql.NewNonNull(graphql.String), validate.Required(), validate.Length(1, 100)),
+			"email":    validate.Arg(graphql.NewNonNull(graphql.String), validate.Required(), validate.Email()),
+			"password": validate.Arg(graphql.NewNonNull(graphql.String), validate.Required(), validate.Password()),
+		},
+		Resolve: legacyMutation(m.Legacy, "signUp", func(p graphql.ResolveParams) (interface{}, error) {
+			return m.signUp(p, p.Args)
+		}),
+	}))
+	b.Mutation("userLogin", m.loginField(userLogin, false))
+	b.Mutation("adminLogin", m.loginField(adminLogin, false))
+	b.Mutation("superAdminLogin", m.loginField(superAdminLogin, false))
+	b.Mutation("logout", &graphql.Field{
+		Type: graphql.Boolean,
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			endSession(p)
+			return true, nil
+		}),
+	})
+	b.Mutation("signUp", withUserErrors(validate.Field(&graphql.Field{
+		Type: SignUpPayloadType,
+		Args: graphql.FieldConfigArgument{
+			"input": &graphql.ArgumentConfig{
+				Type: graphql.NewNonNull(SignupInputType),
+			},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return m.signUp(p, p.Args["input"].(map[string]interface{}))
+		},
+	})))
+	b.Mutation("AddAdmin", validate.Field(&graphql.Field{
+		Type: AdminUserType,
+		Args: graphql.FieldConfigArgument{
+			"name":     validate.Arg(graphql.NewNonNull(graphql.String), validate.Required(), validate.Length(1, 100)),
+			"email":    validate.Arg(graphql.NewNonNull(graphql.String), validate.Required(), validate.Email()),
+			"password": validate.Arg(graphql.NewNonNull(graphql.String), validate.Required(), validate.Password()),
+		},
+		Resolve: m.Auth.SuperAdminMiddleware(m.Audit.Privileged("AddAdmin", func(p graphql.ResolveParams) (interface{}, error) {
+			res, err := m.Client.AddAdmin(p.Context, &pb.UserSignupRequest{
+				Email:    p.Args["email"].(string),
+				Name:     p.Args["name"].(string),
+				Password: p.Args["password"].(string),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return helper.NewAdminUserView(res, identity.RoleAdmin), nil
+		})),
+	}))
+	b.Mutation("AddAddress", validate.Field(&graphql.Field{
+		Type:              AddressType,
+		DeprecationReason: "Use createAddress.",
+		Args: graphql.FieldConfigArgument{
+			"city":     validate.Arg(graphql.String, validate.Required(), validate.Length(1, 100)),
+			"district": validate.Arg(graphql.String, validate.Required(), validate.Length(1, 100)),
+			"state":    validate.Arg(graphql.String, validate.Required(), validate.Length(1, 100)),
+			"road":     validate.Arg(graphql.String, validate.Required(), validate.Length(1, 100)),
+		},
+		Resolve: legacyMutation(m.Legacy, "createAddress", m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			return m.createAddress(p, p.Args)
+		})),
+	}))
+	b.Mutation("createAddress", withUserErrors(validate.Field(&graphql.Field{
+		Type: CreateAddressPayloadType,
+		Args: graphql.FieldConfigArgument{
+			"input": &graphql.ArgumentConfig{
+				Type: graphql.NewNonNull(AddressInputType),
+			},
+		},
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			return m.createAddress(p, p.Args["input"].(map[string]interface{}))
+		}),
+	})))
+	b.Mutation("RemoveAddress", &graphql.Field{
+		Type: AddressType,
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			userIdVal := p.Context.Value("userId").(uint)
+			return m.Client.RemoveAddress(p.Context, &pb.GetUserById{
+				Id: uint32(userIdVal),
+			})
+		}),
+	})
+}
+
+func (m *Users) createAddress(p graphql.ResolveParams, input map[string]interface{}) (interface{}, error) {
+	userIdVal := p.Context.Value("userId").(uint)
+	city, _ := input["city"].(string)
+	state, _ := input["state"].(string)
+	road, _ := input["road"].(string)
+	district, _ := input["district"].(string)
+	return m.Client.AddAddress(p.Context, &pb.AddAddressRequest{
+		UserId:   uint32(userIdVal),
+		City:     city,
+		State:    state,
+		Road:     road,
+		District: district,
+	})
+}
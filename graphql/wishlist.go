@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"io"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/middleware"
+	"github.com/vishnusunil243/api_gateway/validate"
+	"github.com/vishnusunil243/proto-files/pb"
+)
+
+var WishlistType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "wishlist",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"productId": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"userId": &graphql.Field{
+				Type: graphql.Int,
+			},
+		},
+	},
+)
+
+// Wishlist serves the wishlist of the logged in user.
+type Wishlist struct {
+	Client pb.WishlistServiceClient
+	Auth   *middleware.Auth
+}
+
+func (m *Wishlist) Name() string {
+	return "wishlist"
+}
+
+func (m *Wishlist) Register(b *Builder) {
+	b.Query("GetAllWishlist", &graphql.Field{
+		Type: graphql.NewList(WishlistType),
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			userIdval := p.Context.Value("userId").(uint)
+			wishlist, err := m.Client.GetAllWishlistItems(p.Context, &pb.CreateWishlistRequest{
+				UserId: uint32(userIdval),
+			})
+			if err != nil {
+				return nil, err
+			}
+			var res []*pb.GetAllWishlistResponse
+			for {
+				items, err := wishlist.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, items)
+			}
+			return res, nil
+		}),
+	})
+
+	b.Mutation("AddToWishList", validate.Field(&graphql.Field{
+		Type: WishlistType,
+		Args: graphql.FieldConfigArgument{
+			"productId": validate.Arg(graphql.NewNonNull(graphql.Int), validate.Min(1)),
+		},
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			userIdVal := p.Context.Value("userId").(uint)
+			return m.Client.AddToWishlist(p.Context, &pb.AddToWishlistRequest{
+				UserId:    uint32(userIdVal),
+				ProductId: uint32(p.Args["productId"].(int)),
+			})
+		}),
+	}))
+	b.Mutation("RemoveFromWishlist", validate.Field(&graphql.Field{
+		Type: WishlistType,
+		Args: graphql.FieldConfigArgument{
+			"productId": validate.Arg(graphql.NewNonNull(graphql.Int), validate.Min(1)),
+		},
+		Resolve: m.Auth.UserMiddleware(func(p graphql.ResolveParams) (interface{}, error) {
+			userIdVal := p.Context.Value("userId").(uint)
+			return m.Client.RemoveFromWishlist(p.Context, &pb.AddToWishlistRequest{
+				UserId:    uint32(userIdVal),
+				ProductId: uint32(p.Args["productId"].(int)),
+			})
+		}),
+	}))
+}
@@ -0,0 +1,170 @@
+// Package harness boots the full HTTP gateway against the in-memory
+// backends of package fakes, for end to end tests of GraphQL operations,
+// the auth middlewares and error paths:
+//
+//	gw, err := harness.Start(nil)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer gw.Close()
+//	gw.Backends.Products.Add("pen", 10, 5)
+//	res, err := gw.Query(`{ products { name } }`, nil)
+//
+// Gateways share no state: each has its own idempotency keys and, when
+// configure sets them, its own cache, rate limiter, lockouts and audit sink.
+package harness
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/vishnusunil243/api_gateway/authorize"
+	"github.com/vishnusunil243/api_gateway/fakes"
+	"github.com/vishnusunil243/api_gateway/gateway"
+	graph "github.com/vishnusunil243/api_gateway/graphql"
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/grpc"
+)
+
+// Secret signs the session tokens of gateways started by the harness.
+var Secret = []byte("harness")
+
+// Gateway is a gateway listening on a local port.
+type Gateway struct {
+	*httptest.Server
+	Backends *fakes.Backends
+	conn     *grpc.ClientConn
+}
+
+// Start serves a gateway with every module enabled. configure, when not
+// nil, can change the dependencies before the schema is built.
+func Start(configure func(deps *graph.Deps)) (*Gateway, error) {
+	backends := fakes.Start()
+	conn, err := backends.Dial()
+	if err != nil {
+		backends.Close()
+		return nil, err
+	}
+	deps := graph.Deps{
+		Products:        pb.NewProductServiceClient(conn),
+		Users:           pb.NewUserServiceClient(conn),
+		Carts:           pb.NewCartServiceClient(conn),
+		Orders:          pb.NewOrderServiceClient(conn),
+		Wishlists:       pb.NewWishlistServiceClient(conn),
+		Secret:          Secret,
+		LegacyMutations: true,
+	}
+	if configure != nil {
+		configure(&deps)
+	}
+	fail := func(err error) (*Gateway, error) {
+		conn.Close()
+		backends.Close()
+		return nil, err
+	}
+	schema, err := graph.NewSchema(deps)
+	if err != nil {
+		return fail(err)
+	}
+	h, err := gateway.Handler(&schema, deps, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		return fail(err)
+	}
+	return &Gateway{Server: httptest.NewServer(h), Backends: backends, conn: conn}, nil
+}
+
+func (g *Gateway) Close() {
+	g.Server.Close()
+	g.conn.Close()
+	g.Backends.Close()
+}
+
+// Session returns a session cookie as issued by the login mutations.
+func Session(userId uint, admin, superadmin bool) (*http.Cookie, error) {
+	token, err := authorize.GenerateJwt(userId, admin, superadmin, Secret)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Cookie{Name: "jwtToken", Value: token}, nil
+}
+
+// Response is a decoded GraphQL response. Cookies holds the cookies it
+// set, such as the session of a login.
+type Response struct {
+	Status  int
+	Header  http.Header
+	Cookies []*http.Cookie
+	Data    json.RawMessage `json:"data"`
+	Errors  []Error         `json:"errors"`
+}
+
+type Error struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+// Code returns the "code" extension of the error, if any.
+func (e Error) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// Decode unmarshals the data of the response into v.
+func (r *Response) Decode(v interface{}) error {
+	return json.Unmarshal(r.Data, v)
+}
+
+// Query posts a GraphQL operation to /graphql, sending cookies along.
+func (g *Gateway) Query(query string, variables map[string]interface{}, cookies ...*http.Cookie) (*Response, error) {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, g.URL+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return g.do(req, cookies)
+}
+
+// Get sends a GraphQL operation to /graphql in the query string, as links
+// and caches do.
+func (g *Gateway) Get(query string, variables map[string]interface{}, cookies ...*http.Cookie) (*Response, error) {
+	params := url.Values{"query": {query}}
+	if variables != nil {
+		encoded, err := json.Marshal(variables)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("variables", string(encoded))
+	}
+	req, err := http.NewRequest(http.MethodGet, g.URL+"/graphql?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return g.do(req, cookies)
+}
+
+func (g *Gateway) do(req *http.Request, cookies []*http.Cookie) (*Response, error) {
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	resp, err := g.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	res := &Response{Status: resp.StatusCode, Header: resp.Header, Cookies: resp.Cookies()}
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return nil, fmt.Errorf("decoding response with status %d: %s", resp.StatusCode, err.Error())
+	}
+	return res, nil
+}
@@ -0,0 +1,164 @@
+package harness_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/vishnusunil243/api_gateway/fakes"
+	"github.com/vishnusunil243/api_gateway/harness"
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGateway(t *testing.T) {
+	gw, err := harness.Start(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gw.Close()
+	pen := gw.Backends.Products.Add("pen", 10, 5)
+	user, err := gw.Backends.Users.Add("ann", "ann@example.com", "Secret123!", fakes.RoleUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gw.Backends.Carts.CreateCart(context.Background(), &pb.UserCartCreate{UserId: user.Id}); err != nil {
+		t.Fatal(err)
+	}
+	session, err := harness.Session(uint(user.Id), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("anonymous query", func(t *testing.T) {
+		res, err := gw.Query(`query($id: Int!) { product(id: $id) { name price quantity } }`, map[string]interface{}{"id": pen.Id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Errors) > 0 {
+			t.Fatalf("errors: %v", res.Errors)
+		}
+		var data struct {
+			Product struct {
+				Name     string
+				Price    int
+				Quantity int
+			}
+		}
+		if err := res.Decode(&data); err != nil {
+			t.Fatal(err)
+		}
+		if data.Product.Name != "pen" || data.Product.Price != 10 || data.Product.Quantity != 5 {
+			t.Errorf("got product %+v", data.Product)
+		}
+	})
+
+	t.Run("authenticated query", func(t *testing.T) {
+		res, err := gw.Query(`mutation($id: Int!) { AddToCart(productId: $id, quantity: 2) { productId quantity } }`, map[string]interface{}{"id": pen.Id}, session)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Errors) > 0 {
+			t.Fatalf("errors: %v", res.Errors)
+		}
+		items, err := gw.Backends.Carts.Items(user.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || items[0].ProductId != uint32(pen.Id) || items[0].Quantity != 2 {
+			t.Errorf("cart holds %v", items)
+		}
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		res, err := gw.Query(`{ GetAllCartItems { productId } }`, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Errors) != 1 || res.Errors[0].Code() != "UNAUTHENTICATED" {
+			t.Errorf("got errors %v, want UNAUTHENTICATED", res.Errors)
+		}
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		res, err := gw.Query(`{ GetAllOrders { orderId } }`, nil, session)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Errors) != 1 || res.Errors[0].Code() != "FORBIDDEN" {
+			t.Errorf("got errors %v, want FORBIDDEN", res.Errors)
+		}
+	})
+
+	t.Run("audit log limit", func(t *testing.T) {
+		superadmin, err := harness.Session(1, true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := gw.Query(`{ auditLog(limit: 5000) { operation } }`, nil, superadmin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Errors) != 1 || res.Errors[0].Code() != "BAD_USER_INPUT" {
+			t.Errorf("got errors %v, want BAD_USER_INPUT", res.Errors)
+		}
+	})
+
+	t.Run("upstream error", func(t *testing.T) {
+		gw.Backends.Fail("/product.ProductService/GetProduct", status.Error(codes.Unavailable, "product service is down"))
+		defer gw.Backends.Fail("/product.ProductService/GetProduct", nil)
+		res, err := gw.Query(`query($id: Int!) { product(id: $id) { name } }`, map[string]interface{}{"id": pen.Id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "product service is down") {
+			t.Errorf("got errors %v, want the upstream error", res.Errors)
+		}
+	})
+
+	t.Run("state changes over GET", func(t *testing.T) {
+		for _, query := range []string{
+			`mutation { logout }`,
+			`mutation { userLogin(email: "ann@example.com", password: "Secret123!") { expiresAt } }`,
+		} {
+			res, err := gw.Get(query, nil, session)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != http.StatusMethodNotAllowed || len(res.Cookies) > 0 {
+				t.Errorf("%s answered %d setting %v", query, res.Status, res.Cookies)
+			}
+		}
+		res, err := gw.Get(`{ UserLogin(email: "ann@example.com", password: "Secret123!") { id } }`, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Errors) != 1 || res.Errors[0].Code() != "METHOD_NOT_ALLOWED" || len(res.Cookies) > 0 {
+			t.Errorf("deprecated login over GET got errors %v and cookies %v", res.Errors, res.Cookies)
+		}
+		res, err = gw.Get(`query($id: Int!) { product(id: $id) { name } }`, map[string]interface{}{"id": pen.Id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != http.StatusOK || len(res.Errors) > 0 {
+			t.Errorf("query over GET answered %d with %v", res.Status, res.Errors)
+		}
+	})
+
+	t.Run("cache hints", func(t *testing.T) {
+		for query, want := range map[string]string{
+			`query($id: Int!) { product(id: $id) { name } }`:          "public, max-age=60",
+			`query($id: Int!) { product(id: $id) { name quantity } }`: "public, max-age=10",
+		} {
+			res, err := gw.Get(query, map[string]interface{}{"id": pen.Id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := res.Header.Get("Cache-Control"); got != want {
+				t.Errorf("%s: Cache-Control %q, want %q", query, got, want)
+			}
+		}
+	})
+}
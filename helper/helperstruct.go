@@ -1,5 +1,7 @@
 package helper
 
+import "time"
+
 type AddressResponse struct {
 	Id       uint32 `json:"id"`
 	UserID   uint32 `json:"userId"`
@@ -8,3 +10,28 @@ type AddressResponse struct {
 	State    string `json:"state"`
 	Road     string `json:"road"`
 }
+
+// UserProfile is what any caller may see about a user. Upstream messages
+// are always copied field by field into it so that nothing the user
+// service adds later (credential hashes included) leaks through GraphQL.
+type UserProfile struct {
+	Id    uint32 `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// AdminUserView is the account view returned to admins and super admins.
+type AdminUserView struct {
+	Id    uint32 `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// AuthPayload is returned by the login mutations. The session itself only
+// travels in the HttpOnly cookie.
+type AuthPayload struct {
+	User      UserProfile `json:"user"`
+	ExpiresAt time.Time   `json:"expiresAt"`
+	Roles     []string    `json:"roles"`
+}
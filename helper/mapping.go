@@ -0,0 +1,28 @@
+package helper
+
+import "github.com/vishnusunil243/proto-files/pb"
+
+func NewUserProfile(u *pb.UserSignupResponse) UserProfile {
+	return UserProfile{
+		Id:    u.Id,
+		Name:  u.Name,
+		Email: u.Email,
+	}
+}
+
+func NewAdminUserView(u *pb.UserSignupResponse, role string) AdminUserView {
+	return AdminUserView{
+		Id:    u.Id,
+		Name:  u.Name,
+		Email: u.Email,
+		Role:  role,
+	}
+}
+
+func NewAdminUserViews(users []*pb.UserSignupResponse, role string) []AdminUserView {
+	res := make([]AdminUserView, 0, len(users))
+	for _, u := range users {
+		res = append(res, NewAdminUserView(u, role))
+	}
+	return res
+}
@@ -0,0 +1,158 @@
+// Package idempotency lets clients retry a mutation without repeating its
+// effect. The first call with a key runs the mutation and stores its result;
+// later calls with the same key, from the same user and within the TTL,
+// replay the stored result.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/identity"
+	"github.com/vishnusunil243/api_gateway/logging"
+)
+
+const (
+	// Header carries the key on the HTTP request.
+	Header = "Idempotency-Key"
+	// Argument carries the key as a field argument, which wins over Header.
+	Argument = "idempotencyKey"
+
+	MaxKeyLength = 255
+)
+
+// Entry is the stored outcome of a keyed call. Result is empty while the
+// first call is still running.
+type Entry struct {
+	Fingerprint string          `json:"fingerprint"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// Store persists entries. Begin must atomically create a pending entry when
+// none exists, so that concurrent duplicates across gateway instances are
+// detected; it returns the existing entry and false otherwise.
+type Store interface {
+	Begin(ctx context.Context, key string, e Entry, ttl time.Duration) (Entry, bool, error)
+	Complete(ctx context.Context, key string, e Entry, ttl time.Duration) error
+	// Release forgets a pending entry whose call failed so it can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// Keys stores the idempotency keys of one schema for TTL.
+type Keys struct {
+	Store Store
+	TTL   time.Duration
+}
+
+// NewKeys keeps keys in memory for a day. Expired keys are only dropped
+// when reused, run MemoryStore.SweepEvery for long lived gateways.
+func NewKeys() *Keys {
+	return &Keys{Store: NewMemoryStore(), TTL: 24 * time.Hour}
+}
+
+// Error reports a key that cannot be used for this call.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+// Mutation makes next idempotent for calls that carry a key. newResult
+// returns a pointer to the type next resolves to, which is what a replayed
+// result is decoded into. It must be wrapped by one of the auth middlewares
+// since keys are scoped to the caller.
+func (k *Keys) Mutation(operation string, newResult func() interface{}, next graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		key := requestKey(p)
+		if key == "" {
+			return next(p)
+		}
+		if len(key) > MaxKeyLength {
+			return nil, &Error{Code: "BAD_USER_INPUT", Message: fmt.Sprintf("idempotency key must be at most %d characters", MaxKeyLength)}
+		}
+		caller, _ := identity.FromContext(p.Context)
+		storeKey := fmt.Sprintf("%d:%s:%s", caller.UserID, operation, key)
+		fingerprint, err := fingerprint(p.Args)
+		if err != nil {
+			return nil, err
+		}
+		existing, started, err := k.Store.Begin(p.Context, storeKey, Entry{Fingerprint: fingerprint, CreatedAt: time.Now()}, k.TTL)
+		if err != nil {
+			return nil, err
+		}
+		if !started {
+			return replay(existing, fingerprint, newResult)
+		}
+		res, err := next(p)
+		if err != nil {
+			if releaseErr := k.Store.Release(p.Context, storeKey); releaseErr != nil {
+				logging.FromContext(p.Context).Error("failed to release idempotency key", "operation", operation, "error", releaseErr.Error())
+			}
+			return nil, err
+		}
+		encoded, err := json.Marshal(res)
+		if err == nil {
+			err = k.Store.Complete(p.Context, storeKey, Entry{Fingerprint: fingerprint, Result: encoded, CreatedAt: time.Now()}, k.TTL)
+		}
+		if err != nil {
+			// the mutation went through, so its result is returned anyway
+			logging.FromContext(p.Context).Error("failed to store idempotent result", "operation", operation, "error", err.Error())
+		}
+		return res, nil
+	}
+}
+
+func replay(e Entry, fingerprint string, newResult func() interface{}) (interface{}, error) {
+	if e.Fingerprint != fingerprint {
+		return nil, &Error{Code: "IDEMPOTENCY_KEY_REUSED", Message: "idempotency key was already used with different arguments"}
+	}
+	if len(e.Result) == 0 {
+		return nil, &Error{Code: "IDEMPOTENCY_IN_PROGRESS", Message: "a request with this idempotency key is still in progress"}
+	}
+	res := newResult()
+	if err := json.Unmarshal(e.Result, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func requestKey(p graphql.ResolveParams) string {
+	if key, _ := p.Args[Argument].(string); key != "" {
+		return key
+	}
+	if r, ok := p.Context.Value("request").(*http.Request); ok {
+		return r.Header.Get(Header)
+	}
+	return ""
+}
+
+// fingerprint hashes the arguments other than the key itself so that a key
+// reused for a different request is rejected instead of replayed.
+func fingerprint(args map[string]interface{}) (string, error) {
+	rest := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		if k != Argument {
+			rest[k] = v
+		}
+	}
+	encoded, err := json.Marshal(rest)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,69 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps entries in process. It only deduplicates requests that
+// reach the same gateway instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	Entry
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]memoryEntry{}}
+}
+
+func (s *MemoryStore) Begin(_ context.Context, key string, e Entry, ttl time.Duration) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if existing, ok := s.entries[key]; ok && !now.After(existing.expires) {
+		return existing.Entry, false, nil
+	}
+	s.entries[key] = memoryEntry{Entry: e, expires: now.Add(ttl)}
+	return e, true, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key string, e Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryEntry{Entry: e, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// SweepEvery drops the expired entries on every tick until ctx is done,
+// entries are otherwise only replaced when their key is used again.
+func (s *MemoryStore) SweepEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, e := range s.entries {
+				if now.After(e.expires) {
+					delete(s.entries, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
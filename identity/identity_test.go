@@ -0,0 +1,34 @@
+package identity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	key := []byte("identity key")
+	now := time.Now()
+	for _, tc := range []struct {
+		name     string
+		issuedAt time.Time
+		ok       bool
+	}{
+		{"fresh", now, true},
+		{"slightly ahead", now.Add(MaxClockSkew / 2), true},
+		{"future", now.Add(time.Hour), false},
+		{"expired", now.Add(-DefaultMaxAge - time.Minute), false},
+	} {
+		value, err := Sign(Identity{UserID: 1, Roles: []string{RoleUser}, IssuedAt: tc.issuedAt.Unix()}, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Verify(value, key, DefaultMaxAge)
+		if ok := err == nil; ok != tc.ok {
+			t.Errorf("%s: got %v", tc.name, err)
+		}
+	}
+	value, _ := Sign(Identity{UserID: 1}, key)
+	if _, err := Verify(value, []byte("other key"), DefaultMaxAge); err == nil {
+		t.Error("verified with the wrong key")
+	}
+}
@@ -0,0 +1,95 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/vishnusunil243/api_gateway/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor attaches the signed identity and the request ID
+// found in the call context to the outgoing metadata.
+func UnaryClientInterceptor(key []byte) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, err := outgoing(ctx, key)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func StreamClientInterceptor(key []byte) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := outgoing(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+func outgoing(ctx context.Context, key []byte) (context.Context, error) {
+	if id := requestid.FromContext(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, requestid.MetadataKey, id)
+	}
+	id, ok := FromContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+	signed, err := Sign(id, key)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, signed), nil
+}
+
+// UnaryServerInterceptor is meant for backends: a valid identity is stored
+// in the handler context, an invalid one rejects the call and calls without
+// one pass through untouched (e.g. logins).
+func UnaryServerInterceptor(key []byte) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := incoming(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(key []byte) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := incoming(ss.Context(), key)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func incoming(ctx context.Context, key []byte) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(requestid.MetadataKey); len(ids) > 0 {
+		ctx = requestid.NewContext(ctx, ids[0])
+	}
+	if len(md.Get(MetadataKey)) == 0 {
+		return ctx, nil
+	}
+	id, err := FromIncomingContext(ctx, key)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return NewContext(ctx, id), nil
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
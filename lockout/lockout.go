@@ -0,0 +1,228 @@
+// Package lockout protects the login operations against password guessing.
+// Failed logins are counted per email and per client IP; each failure delays
+// the next attempt a little more and reaching the threshold locks the email
+// or IP for a while. Admin and super admin logins use stricter thresholds.
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/logging"
+	"github.com/vishnusunil243/api_gateway/metrics"
+	"github.com/vishnusunil243/api_gateway/ratelimit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	KindUser       = "user"
+	KindAdmin      = "admin"
+	KindSuperAdmin = "superadmin"
+
+	ScopeEmail = "email"
+	ScopeIP    = "ip"
+)
+
+// Policy configures one kind of login. The IP threshold is higher than the
+// email one since several users may share an address.
+type Policy struct {
+	Email Threshold
+	IP    Threshold
+	// BaseDelay is the delay after the first failure; it doubles with each
+	// further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func (p Policy) delay(failures int) time.Duration {
+	if failures < 1 || p.BaseDelay <= 0 {
+		return 0
+	}
+	d := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(failures-1)))
+	if d > p.MaxDelay || d <= 0 {
+		return p.MaxDelay
+	}
+	return d
+}
+
+func DefaultPolicies() map[string]Policy {
+	return map[string]Policy{
+		KindUser: {
+			Email:     Threshold{Max: 5, Window: 15 * time.Minute, Lock: 15 * time.Minute},
+			IP:        Threshold{Max: 20, Window: 15 * time.Minute, Lock: 15 * time.Minute},
+			BaseDelay: 250 * time.Millisecond,
+			MaxDelay:  4 * time.Second,
+		},
+		KindAdmin: {
+			Email:     Threshold{Max: 3, Window: 30 * time.Minute, Lock: 30 * time.Minute},
+			IP:        Threshold{Max: 10, Window: 30 * time.Minute, Lock: 30 * time.Minute},
+			BaseDelay: 500 * time.Millisecond,
+			MaxDelay:  8 * time.Second,
+		},
+		KindSuperAdmin: {
+			Email:     Threshold{Max: 3, Window: time.Hour, Lock: time.Hour},
+			IP:        Threshold{Max: 5, Window: time.Hour, Lock: time.Hour},
+			BaseDelay: time.Second,
+			MaxDelay:  8 * time.Second,
+		},
+	}
+}
+
+// Tracker counts the failed logins of one gateway. A nil *Tracker does not
+// track logins.
+type Tracker struct {
+	Store    Store
+	Policies map[string]Policy
+	// TrustProxy takes the client address from the last X-Forwarded-For
+	// entry, see ratelimit.ClientIP.
+	TrustProxy bool
+}
+
+// Error is returned for logins against a locked email or IP. It does not say
+// which one is locked or whether the account exists.
+type Error struct {
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %d minutes", int(math.Ceil(e.RetryAfter.Minutes())))
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":       "LOGIN_LOCKED",
+		"retryAfter": int(math.Ceil(e.RetryAfter.Seconds())),
+	}
+}
+
+// Login tracks the outcome of a login resolver whose "email" argument names
+// the account. Every attempt is counted as a failure before upstream is
+// called, so concurrent guesses cannot all slip under the threshold, and
+// given back unless the credentials were rejected. Locked callers are
+// rejected before upstream is called.
+func (t *Tracker) Login(kind string, next graphql.FieldResolveFn) graphql.FieldResolveFn {
+	if t == nil {
+		return next
+	}
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		email, _ := p.Args["email"].(string)
+		keys := []State{{Kind: kind, Scope: ScopeEmail, Value: strings.ToLower(email)}}
+		if r, ok := p.Context.Value("request").(*http.Request); ok {
+			keys = append(keys, State{Kind: kind, Scope: ScopeIP, Value: ratelimit.ClientIP(r, t.TrustProxy)})
+		}
+		states, err := t.attempt(p.Context, keys)
+		if err != nil {
+			return nil, err
+		}
+		res, err := next(p)
+		switch {
+		case err == nil:
+			// only the email is cleared, an IP keeps its earlier failures
+			if resetErr := t.Store.Reset(p.Context, keys[0]); resetErr != nil {
+				logging.FromContext(p.Context).Error("failed to reset login failures", "error", resetErr.Error())
+			}
+			t.release(p.Context, keys[1:])
+		case credentialsRejected(err):
+			t.locked(p.Context, states)
+		default:
+			t.release(p.Context, keys)
+		}
+		return res, err
+	}
+}
+
+func (t *Tracker) threshold(key State) Threshold {
+	if key.Scope == ScopeIP {
+		return t.Policies[key.Kind].IP
+	}
+	return t.Policies[key.Kind].Email
+}
+
+// attempt counts the attempt against every key, rejecting it when one is
+// locked, and then waits for the progressive delay of the key with the most
+// earlier failures.
+func (t *Tracker) attempt(ctx context.Context, keys []State) ([]State, error) {
+	now := time.Now()
+	var states []State
+	for i, key := range keys {
+		st, ok, err := t.Store.Attempt(ctx, key, t.threshold(key))
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to record login attempt", "error", err.Error())
+			st = key
+		} else if !ok {
+			t.release(ctx, keys[:i])
+			return nil, &Error{RetryAfter: st.LockedUntil.Sub(now)}
+		}
+		states = append(states, st)
+	}
+	failures := 0
+	for _, st := range states {
+		if st.Failures-1 > failures {
+			failures = st.Failures - 1
+		}
+	}
+	delay := t.Policies[keys[0].Kind].delay(failures)
+	if delay == 0 {
+		return states, nil
+	}
+	select {
+	case <-ctx.Done():
+		t.release(ctx, keys)
+		return nil, ctx.Err()
+	case <-time.After(delay):
+		return states, nil
+	}
+}
+
+func (t *Tracker) release(ctx context.Context, keys []State) {
+	for _, key := range keys {
+		if err := t.Store.Release(context.WithoutCancel(ctx), key, t.threshold(key)); err != nil {
+			logging.FromContext(ctx).Error("failed to release login attempt", "error", err.Error())
+		}
+	}
+}
+
+// locked reports the keys the failed attempt locked.
+func (t *Tracker) locked(ctx context.Context, states []State) {
+	for _, st := range states {
+		if st.Failures == t.threshold(st).Max {
+			metrics.LoginLockouts.WithLabelValues(st.Kind, st.Scope).Inc()
+			logging.FromContext(ctx).Warn("login locked", "kind", st.Kind, "scope", st.Scope, "until", st.LockedUntil)
+		}
+	}
+}
+
+// credentialsRejected tells failed credentials apart from upstream outages,
+// which must not count against the caller.
+func credentialsRejected(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled, codes.ResourceExhausted, codes.Internal:
+		return false
+	}
+	return true
+}
+
+// List returns the tracked failures and locks, most recent first.
+func (t *Tracker) List(ctx context.Context) ([]State, error) {
+	if t == nil {
+		return nil, fmt.Errorf("login lockout is disabled")
+	}
+	return t.Store.List(ctx)
+}
+
+// Clear forgets the failures and lock of one key.
+func (t *Tracker) Clear(ctx context.Context, key State) error {
+	if t == nil {
+		return fmt.Errorf("login lockout is disabled")
+	}
+	if key.Scope == ScopeEmail {
+		key.Value = strings.ToLower(key.Value)
+	}
+	return t.Store.Reset(ctx, key)
+}
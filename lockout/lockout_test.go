@@ -0,0 +1,46 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoginForgedForwardedFor(t *testing.T) {
+	tracker := &Tracker{
+		Store: NewMemoryStore(),
+		Policies: map[string]Policy{KindUser: {
+			Email: Threshold{Max: 100, Window: time.Minute, Lock: time.Minute},
+			IP:    Threshold{Max: 3, Window: time.Minute, Lock: time.Minute},
+		}},
+		TrustProxy: true,
+	}
+	calls := 0
+	login := tracker.Login(KindUser, func(graphql.ResolveParams) (interface{}, error) {
+		calls++
+		return nil, status.Error(codes.Unauthenticated, "invalid email or password")
+	})
+	for i := 0; i < 5; i++ {
+		r := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		r.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d, 198.51.100.7", i))
+		_, err := login(graphql.ResolveParams{
+			Context: context.WithValue(context.Background(), "request", r),
+			Args:    map[string]interface{}{"email": fmt.Sprintf("user%d@example.com", i)},
+		})
+		var locked *Error
+		if isLocked := errors.As(err, &locked); isLocked != (i >= 3) {
+			t.Errorf("attempt %d: got %v", i, err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("upstream called %d times, want 3", calls)
+	}
+}
@@ -0,0 +1,168 @@
+package lockout
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// State is the failure record of one login key.
+type State struct {
+	Kind        string    `json:"kind"`
+	Scope       string    `json:"scope"`
+	Value       string    `json:"value"`
+	Failures    int       `json:"failures"`
+	LastFailure time.Time `json:"lastFailure"`
+	LockedUntil time.Time `json:"lockedUntil"`
+}
+
+func (s State) Key() string {
+	return s.Kind + ":" + s.Scope + ":" + s.Value
+}
+
+func (s State) Locked(now time.Time) bool {
+	return now.Before(s.LockedUntil)
+}
+
+// Threshold locks a key for Lock once it reaches Max failures with less
+// than Window between consecutive ones.
+type Threshold struct {
+	Max    int
+	Window time.Duration
+	Lock   time.Duration
+}
+
+// Store keeps failure records. A login attempt is counted as a failure
+// before upstream is called and given back if it turns out not to be one,
+// so Attempt and Release must update a record atomically for concurrent
+// guesses, also across gateway instances sharing an implementation.
+type Store interface {
+	Get(ctx context.Context, key State) (State, error)
+	// Attempt counts an attempt against an unlocked key, locking it when the
+	// attempt reaches the threshold. A locked key is returned unchanged and
+	// false.
+	Attempt(ctx context.Context, key State, t Threshold) (State, bool, error)
+	// Release takes back an attempt that did not fail, lifting the lock it
+	// may have set.
+	Release(ctx context.Context, key State, t Threshold) error
+	Reset(ctx context.Context, key State) error
+	// List returns the records with failures or an active lock.
+	List(ctx context.Context) ([]State, error)
+}
+
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]memoryState
+}
+
+type memoryState struct {
+	State
+	window time.Duration
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: map[string]memoryState{}}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key State) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.live(key.Key(), time.Now()); ok {
+		return st.State, nil
+	}
+	return key, nil
+}
+
+func (s *MemoryStore) Attempt(_ context.Context, key State, t Threshold) (State, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	st, ok := s.live(key.Key(), now)
+	if ok && st.Locked(now) {
+		return st.State, false, nil
+	}
+	if !ok || !st.LockedUntil.IsZero() {
+		// a served lock starts the count again
+		st = memoryState{State: key}
+	}
+	st.window = t.Window
+	st.Failures++
+	st.LastFailure = now
+	if st.Failures >= t.Max {
+		st.LockedUntil = now.Add(t.Lock)
+	}
+	s.states[key.Key()] = st
+	return st.State, true, nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key State, t Threshold) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.live(key.Key(), time.Now())
+	if !ok || st.Failures == 0 {
+		return nil
+	}
+	st.Failures--
+	if st.Failures < t.Max {
+		st.LockedUntil = time.Time{}
+	}
+	s.states[key.Key()] = st
+	return nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, key.Key())
+	return nil
+}
+
+func (s *MemoryStore) List(_ context.Context) ([]State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var res []State
+	for key := range s.states {
+		if st, ok := s.live(key, now); ok {
+			res = append(res, st.State)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].LastFailure.After(res[j].LastFailure)
+	})
+	return res, nil
+}
+
+// SweepEvery drops the expired records on every tick until ctx is done,
+// records are otherwise only dropped when their key is used again.
+func (s *MemoryStore) SweepEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key := range s.states {
+				s.live(key, now)
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// live returns the record for key unless it has expired, dropping it when
+// it has: no lock is active and the window since the last failure is over.
+func (s *MemoryStore) live(key string, now time.Time) (memoryState, bool) {
+	st, ok := s.states[key]
+	if !ok {
+		return st, false
+	}
+	if !st.Locked(now) && now.Sub(st.LastFailure) > st.window {
+		delete(s.states, key)
+		return st, false
+	}
+	return st, true
+}
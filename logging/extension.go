@@ -0,0 +1,108 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Extension tags the request logger with the GraphQL operation and root
+// field, logs resolver errors and writes a summary line per operation.
+type Extension struct{}
+
+type operationKey struct{}
+
+type operation struct {
+	start  time.Time
+	base   *slog.Logger
+	name   string
+	kind   string
+	errors int
+}
+
+func (Extension) Name() string {
+	return "logging"
+}
+
+func (Extension) Init(ctx context.Context, p *graphql.Params) context.Context {
+	return context.WithValue(ctx, operationKey{}, &operation{
+		start: time.Now(),
+		base:  FromContext(ctx),
+		name:  p.OperationName,
+	})
+}
+
+func (Extension) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(err error) {
+		if err != nil {
+			FromContext(ctx).Info("graphql parse failed", "error", err.Error())
+		}
+	}
+}
+
+func (Extension) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func(errs []gqlerrors.FormattedError) {
+		if len(errs) > 0 {
+			FromContext(ctx).Info("graphql validation failed", "operation", operationName(ctx), "errors", len(errs))
+		}
+	}
+}
+
+func (Extension) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(res *graphql.Result) {
+		op, ok := ctx.Value(operationKey{}).(*operation)
+		if !ok {
+			return
+		}
+		op.base.Info("graphql operation",
+			"operation", op.name,
+			"type", op.kind,
+			"errors", op.errors,
+			"duration_ms", time.Since(op.start).Milliseconds(),
+		)
+	}
+}
+
+// ResolveFieldDidStart derives the logger for each root field from the
+// request logger so that attributes do not pile up across fields.
+func (Extension) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	op, ok := ctx.Value(operationKey{}).(*operation)
+	if !ok {
+		return ctx, func(interface{}, error) {}
+	}
+	if info.Path.Prev == nil {
+		if def, ok := info.Operation.(*ast.OperationDefinition); ok {
+			op.kind = def.Operation
+			if def.Name != nil {
+				op.name = def.Name.Value
+			}
+		}
+		ctx = NewContext(ctx, op.base.With("operation", op.name, "field", info.FieldName))
+	}
+	logger := FromContext(ctx)
+	return ctx, func(_ interface{}, err error) {
+		if err != nil {
+			op.errors++
+			logger.Warn("resolver failed", "path", info.Path.AsArray(), "error", err.Error())
+		}
+	}
+}
+
+func (Extension) HasResult() bool {
+	return false
+}
+
+func (Extension) GetResult(context.Context) interface{} {
+	return nil
+}
+
+func operationName(ctx context.Context) string {
+	if op, ok := ctx.Value(operationKey{}).(*operation); ok {
+		return op.name
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor logs every call to the named upstream with the
+// request logger, at debug level on success and warn level on failure.
+func UnaryClientInterceptor(upstream string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logCall(ctx, upstream, method, start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor logs when a stream could not be opened; failures
+// while receiving surface through the resolver error log.
+func StreamClientInterceptor(upstream string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		logCall(ctx, upstream, method, start, err)
+		return stream, err
+	}
+}
+
+func logCall(ctx context.Context, upstream, method string, start time.Time, err error) {
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	FromContext(ctx).Log(ctx, level, "upstream call",
+		"upstream", upstream,
+		"method", method,
+		"code", status.Code(err).String(),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+var (
+	operationsMu sync.RWMutex
+	operations   = map[string]bool{}
+)
+
+// RegisterOperations adds operation names to the ones recorded as
+// themselves. Operation names come from the client, so any other name is
+// recorded as "other" to keep the number of series bounded.
+func RegisterOperations(names ...string) {
+	operationsMu.Lock()
+	defer operationsMu.Unlock()
+	for _, name := range names {
+		operations[name] = true
+	}
+}
+
+func operationLabel(name string) string {
+	if name == "" {
+		return "anonymous"
+	}
+	operationsMu.RLock()
+	defer operationsMu.RUnlock()
+	if operations[name] {
+		return name
+	}
+	return "other"
+}
+
+// Extension records GraphQL operation latency and resolver errors. Add it to
+// the schema with Schema.AddExtensions.
+type Extension struct{}
+
+type operationKey struct{}
+
+type operation struct {
+	start time.Time
+	name  string
+	kind  string
+}
+
+func (Extension) Name() string {
+	return "metrics"
+}
+
+func (Extension) Init(ctx context.Context, p *graphql.Params) context.Context {
+	return context.WithValue(ctx, operationKey{}, &operation{
+		start: time.Now(),
+		name:  operationLabel(p.OperationName),
+		kind:  "invalid",
+	})
+}
+
+func (Extension) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(err error) {
+		if err != nil {
+			observe(ctx)
+		}
+	}
+}
+
+func (Extension) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func(errs []gqlerrors.FormattedError) {
+		if len(errs) > 0 {
+			observe(ctx)
+		}
+	}
+}
+
+func (Extension) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(*graphql.Result) {
+		observe(ctx)
+	}
+}
+
+func (Extension) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	if op, ok := ctx.Value(operationKey{}).(*operation); ok && op.kind == "invalid" {
+		if def, ok := info.Operation.(*ast.OperationDefinition); ok {
+			op.kind = def.Operation
+			if def.Name != nil {
+				op.name = operationLabel(def.Name.Value)
+			}
+		}
+	}
+	return ctx, func(_ interface{}, err error) {
+		if err != nil {
+			ResolverErrors.WithLabelValues(info.ParentType.Name()+"."+info.FieldName, ErrorCode(err)).Inc()
+		}
+	}
+}
+
+func (Extension) HasResult() bool {
+	return false
+}
+
+func (Extension) GetResult(context.Context) interface{} {
+	return nil
+}
+
+func observe(ctx context.Context) {
+	op, ok := ctx.Value(operationKey{}).(*operation)
+	if !ok {
+		return
+	}
+	OperationDuration.WithLabelValues(op.name, op.kind).Observe(time.Since(op.start).Seconds())
+}
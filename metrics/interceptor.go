@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/vishnusunil243/api_gateway/clientstream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor records latency and status of unary calls made to
+// the named upstream.
+func UnaryClientInterceptor(upstream string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		inFlight := UpstreamInFlight.WithLabelValues(upstream)
+		inFlight.Inc()
+		defer inFlight.Dec()
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		UpstreamDuration.WithLabelValues(upstream, method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// StreamClientInterceptor records server streams once they are drained, fail
+// or are abandoned with their context.
+func StreamClientInterceptor(upstream string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		inFlight := UpstreamInFlight.WithLabelValues(upstream)
+		inFlight.Inc()
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			inFlight.Dec()
+			UpstreamDuration.WithLabelValues(upstream, method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+			return nil, err
+		}
+		return clientstream.Watch(stream, func(err error) {
+			inFlight.Dec()
+			UpstreamDuration.WithLabelValues(upstream, method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		}), nil
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "gateway"
+
+var (
+	OperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "graphql_operation_duration_seconds",
+		Help:      "Latency of GraphQL operations by operation name and type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "type"})
+
+	ResolverErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "graphql_resolver_errors_total",
+		Help:      "Errors returned by GraphQL resolvers by field and error code.",
+	}, []string{"field", "code"})
+
+	UpstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_rpc_duration_seconds",
+		Help:      "Latency of gRPC calls to backend services by upstream, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"upstream", "method", "code"})
+
+	UpstreamInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "upstream_rpcs_in_flight",
+		Help:      "gRPC calls to backend services currently in progress.",
+	}, []string{"upstream"})
+
+	HTTPInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests currently being served.",
+	})
+
+	AuthFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_failures_total",
+		Help:      "Requests rejected by the auth middlewares by required role.",
+	}, []string{"role"})
+
+	RateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected by the rate limiter by scope.",
+	}, []string{"scope"})
+
+	LoginLockouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_lockouts_total",
+		Help:      "Login keys locked after repeated failures by login kind and scope.",
+	}, []string{"kind", "scope"})
+
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Lookups of cached fields by field and result (hit, stale or miss).",
+	}, []string{"field", "result"})
+)
+
+// Handler serves the registered metrics for scraping.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// InFlight tracks the number of HTTP requests being served by next.
+func InFlight(next http.Handler) http.Handler {
+	return promhttp.InstrumentHandlerInFlight(HTTPInFlight, next)
+}
+
+// ErrorCode classifies a resolver error: an explicit "code" extension wins,
+// then the gRPC status of an upstream error, and INTERNAL otherwise.
+func ErrorCode(err error) string {
+	var extended gqlerrors.ExtendedError
+	if errors.As(err, &extended) {
+		if code, ok := extended.Extensions()["code"].(string); ok {
+			return code
+		}
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		return s.Code().String()
+	}
+	return "INTERNAL"
+}
@@ -7,94 +7,156 @@ import (
 
 	"github.com/graphql-go/graphql"
 	"github.com/vishnusunil243/api_gateway/authorize"
+	"github.com/vishnusunil243/api_gateway/identity"
+	"github.com/vishnusunil243/api_gateway/logging"
+	"github.com/vishnusunil243/api_gateway/metrics"
 )
 
-var (
+// Auth checks the session cookie of a request against the secret its
+// tokens are signed with.
+type Auth struct {
 	secret []byte
-)
+}
+
+func NewAuth(secret []byte) *Auth {
+	return &Auth{secret: secret}
+}
+
+// AuthError is returned when the caller is not logged in (UNAUTHENTICATED)
+// or lacks the role a field requires (FORBIDDEN).
+type AuthError struct {
+	Code string
+	Err  error
+}
 
-func InitMiddlewareSecret(secretString string) {
-	secret = []byte(secretString)
+func (e *AuthError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AuthError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+// authFailed counts a rejected request for the role the field requires.
+func authFailed(ctx context.Context, role string, err error) (interface{}, error) {
+	return reject(ctx, role, &AuthError{Code: "UNAUTHENTICATED", Err: err})
+}
+
+func forbidden(ctx context.Context, role string, err error) (interface{}, error) {
+	return reject(ctx, role, &AuthError{Code: "FORBIDDEN", Err: err})
+}
+
+func reject(ctx context.Context, role string, err *AuthError) (interface{}, error) {
+	metrics.AuthFailures.WithLabelValues(role).Inc()
+	logging.FromContext(ctx).Info("authorization failed", "role", role, "code", err.Code, "error", err.Error())
+	return nil, err
+}
+
+// identityFromClaims builds the identity forwarded to the backends from
+// validated token claims.
+func identityFromClaims(auth map[string]interface{}) identity.Identity {
+	roles := []string{identity.RoleUser}
+	if auth["isadmin"].(bool) {
+		roles = append(roles, identity.RoleAdmin)
+	}
+	if auth["isuadmin"].(bool) {
+		roles = append(roles, identity.RoleSuperAdmin)
+	}
+	tokenId, _ := auth["tokenId"].(string)
+	return identity.Identity{
+		UserID:  uint32(auth["userId"].(uint)),
+		Roles:   roles,
+		TokenID: tokenId,
+	}
 }
-func AdminMiddleware(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+func (a *Auth) AdminMiddleware(next graphql.FieldResolveFn) graphql.FieldResolveFn {
 	return func(p graphql.ResolveParams) (interface{}, error) {
 		r := p.Context.Value("request").(*http.Request)
 		cookie, err := r.Cookie("jwtToken")
 		if err != nil {
-			return nil, err
+			return authFailed(p.Context, "admin", err)
 		}
 		if cookie == nil {
-			return nil, fmt.Errorf("you are not logged in")
+			return authFailed(p.Context, "admin", fmt.Errorf("you are not logged in"))
 		}
 		ctx := p.Context
 		token := cookie.Value
-		auth, err := authorize.ValidateToken(token, secret)
+		auth, err := authorize.ValidateToken(token, a.secret)
 		if err != nil {
-			fmt.Println(err.Error())
-			return nil, err
+			return authFailed(p.Context, "admin", err)
 		}
 		userIdval := auth["userId"].(uint)
 		if userIdval < 1 {
-			return nil, fmt.Errorf("invalid userId")
+			return authFailed(p.Context, "admin", fmt.Errorf("invalid userId"))
 		}
 		if !auth["isadmin"].(bool) {
-			return nil, fmt.Errorf("you are not an admin to perform this action")
+			return forbidden(p.Context, "admin", fmt.Errorf("you are not an admin to perform this action"))
 		}
 		ctx = context.WithValue(ctx, "userId", userIdval)
+		ctx = identity.NewContext(ctx, identityFromClaims(auth))
+		ctx = logging.With(ctx, "user_id", auth["userId"])
 		p.Context = ctx
 		return next(p)
 	}
 }
-func SuperAdminMiddleware(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+func (a *Auth) SuperAdminMiddleware(next graphql.FieldResolveFn) graphql.FieldResolveFn {
 	return func(p graphql.ResolveParams) (interface{}, error) {
 		r := p.Context.Value("request").(*http.Request)
 		cookie, err := r.Cookie("jwtToken")
 		if err != nil {
-			return nil, err
+			return authFailed(p.Context, "superadmin", err)
 		}
 		if cookie == nil {
-			return nil, fmt.Errorf("please login to perform this action")
+			return authFailed(p.Context, "superadmin", fmt.Errorf("please login to perform this action"))
 		}
 		ctx := p.Context
 		token := cookie.Value
-		auth, err := authorize.ValidateToken(token, secret)
+		auth, err := authorize.ValidateToken(token, a.secret)
 		if err != nil {
-			return nil, err
+			return authFailed(p.Context, "superadmin", err)
 		}
 		userIdVal := auth["userId"].(uint)
 		if userIdVal < 1 {
-			return nil, fmt.Errorf("invalid userid")
+			return authFailed(p.Context, "superadmin", fmt.Errorf("invalid userid"))
 		}
 		if !auth["isuadmin"].(bool) {
-			return nil, fmt.Errorf("you are not a super admin to perform this action")
+			return forbidden(p.Context, "superadmin", fmt.Errorf("you are not a super admin to perform this action"))
 		}
 		ctx = context.WithValue(ctx, "userId", userIdVal)
+		ctx = identity.NewContext(ctx, identityFromClaims(auth))
+		ctx = logging.With(ctx, "user_id", auth["userId"])
 		p.Context = ctx
 		return next(p)
 	}
 }
-func UserMiddleware(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+func (a *Auth) UserMiddleware(next graphql.FieldResolveFn) graphql.FieldResolveFn {
 	return func(p graphql.ResolveParams) (interface{}, error) {
 		r := p.Context.Value("request").(*http.Request)
 		cookie, err := r.Cookie("jwtToken")
 
 		if cookie == nil {
-			return nil, fmt.Errorf("please log in to perform this function")
+			return authFailed(p.Context, "user", fmt.Errorf("please log in to perform this function"))
 		}
 		if err != nil {
-			return nil, err
+			return authFailed(p.Context, "user", err)
 		}
 		ctx := p.Context
 		token := cookie.Value
-		auth, err := authorize.ValidateToken(token, secret)
+		auth, err := authorize.ValidateToken(token, a.secret)
 		if err != nil {
-			return nil, err
+			return authFailed(p.Context, "user", err)
 		}
 		userIdVal := auth["userId"].(uint)
 		if userIdVal < 1 {
-			return nil, fmt.Errorf("invalid user id")
+			return authFailed(p.Context, "user", fmt.Errorf("invalid user id"))
 		}
 		ctx = context.WithValue(ctx, "userId", userIdVal)
+		ctx = identity.NewContext(ctx, identityFromClaims(auth))
+		ctx = logging.With(ctx, "user_id", auth["userId"])
 		p.Context = ctx
 		return next(p)
 	}
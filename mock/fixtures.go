@@ -0,0 +1,129 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Fixtures override the generated responses, by full method name such as
+// "/product.ProductService/GetProduct":
+//
+//	{"methods": {
+//	  "/product.ProductService/GetProduct": {"response": {"name": "Kettle"}},
+//	  "/product.ProductService/GetAllProducts": {"count": 2, "responses": [{"price": 10}]},
+//	  "/user.UserService/AdminLogin": {"code": 16, "message": "invalid email or password"}
+//	}}
+//
+// Response fields replace those of the generated message, so a fixture only
+// lists the fields it pins; a repeated field lists all of its elements.
+//
+// Most GraphQL fields call the method of the same name on their service,
+// e.g. AddToCart calls /cart.CartService/AddToCart. The others call, in
+// order:
+//
+//	products           /product.ProductService/GetAllProducts
+//	product            /product.ProductService/GetProduct
+//	createProduct      /product.ProductService/AddProduct
+//	GetAllWishlist     /wishlist.WishlistService/GetAllWishlistItems
+//	AddToWishList      /wishlist.WishlistService/AddToWishlist
+//	userLogin          /user.UserService/UserLogin
+//	adminLogin         /user.UserService/AdminLogin
+//	superAdminLogin    /user.UserService/SuperAdminLogin
+//	signUp             /user.UserService/UserSignup /cart.CartService/CreateCart /wishlist.WishlistService/CreateWishlist
+//	UserSignup         /user.UserService/UserSignup /cart.CartService/CreateCart /wishlist.WishlistService/CreateWishlist
+//	createAddress      /user.UserService/AddAddress
+//	UserCancelOrder    /order.OrderService/GetOrder /order.OrderService/UserCancelOrder
+//	ChangeOrderStatus  /order.OrderService/GetOrder /order.OrderService/ChangeOrderStatus
+//
+// Fields that only read the gateway's own state call nothing.
+type Fixtures struct {
+	Methods map[string]Fixture `json:"methods"`
+}
+
+// Fixture overrides the responses of one method.
+type Fixture struct {
+	// Count is the number of messages a stream sends, len(Responses) or
+	// DefaultCount when zero.
+	Count int `json:"count,omitempty"`
+	// Response is merged over every response.
+	Response json.RawMessage `json:"response,omitempty"`
+	// Responses are merged over the responses of a stream by position,
+	// after Response.
+	Responses []json.RawMessage `json:"responses,omitempty"`
+	// Code fails the call with this status instead.
+	Code    codes.Code `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+// Err is the status the fixture fails calls with.
+func (f Fixture) Err() error {
+	if f.Code == codes.OK {
+		return nil
+	}
+	return status.Error(f.Code, f.Message)
+}
+
+func (f Fixture) count() int {
+	switch {
+	case f.Count > 0:
+		return f.Count
+	case len(f.Responses) > 0:
+		return len(f.Responses)
+	}
+	return DefaultCount
+}
+
+// apply sets the fields of the overrides of the i-th response on reply.
+func (f Fixture) apply(i int, reply proto.Message) error {
+	overrides := []json.RawMessage{f.Response}
+	if i < len(f.Responses) {
+		overrides = append(overrides, f.Responses[i])
+	}
+	for _, data := range overrides {
+		if len(data) == 0 {
+			continue
+		}
+		msg := reply.ProtoReflect()
+		override := msg.New().Interface()
+		var fields map[string]json.RawMessage
+		if err := protojson.Unmarshal(data, override); err != nil {
+			return status.Errorf(codes.Internal, "mock: invalid fixture for %s: %s", msg.Descriptor().FullName(), err.Error())
+		}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return status.Errorf(codes.Internal, "mock: invalid fixture for %s: %s", msg.Descriptor().FullName(), err.Error())
+		}
+		// merging would append to repeated fields and skip zero values
+		descriptors := msg.Descriptor().Fields()
+		for name := range fields {
+			fd := descriptors.ByJSONName(name)
+			if fd == nil {
+				fd = descriptors.ByName(protoreflect.Name(name))
+			}
+			if fd != nil {
+				msg.Clear(fd)
+			}
+		}
+		proto.Merge(reply, override)
+	}
+	return nil
+}
+
+// LoadFixtures reads the fixtures file at path.
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("invalid mock fixtures %s: %s", path, err.Error())
+	}
+	return &fixtures, nil
+}
@@ -0,0 +1,192 @@
+package mock_test
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vishnusunil243/api_gateway/audit"
+	graph "github.com/vishnusunil243/api_gateway/graphql"
+	"github.com/vishnusunil243/api_gateway/harness"
+	"github.com/vishnusunil243/api_gateway/lockout"
+	"github.com/vishnusunil243/api_gateway/mock"
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/grpc"
+)
+
+// operations call every root field with arguments that pass validation.
+var operations = map[string]string{
+	"AdminLogin":         `{ AdminLogin(email: "a@example.com", password: "secret") { id } }`,
+	"GetAddress":         `{ GetAddress { id } }`,
+	"GetAdmin":           `{ GetAdmin(id: 1) { id } }`,
+	"GetAllAdmins":       `{ GetAllAdmins { id } }`,
+	"GetAllCartItems":    `{ GetAllCartItems { productId } }`,
+	"GetAllOrders":       `{ GetAllOrders { orderId } }`,
+	"GetAllOrdersUser":   `{ GetAllOrdersUser { orderId } }`,
+	"GetAllUsers":        `{ GetAllUsers { id } }`,
+	"GetAllWishlist":     `{ GetAllWishlist { productId } }`,
+	"GetOrder":           `{ GetOrder(orderId: 1) { orderId } }`,
+	"GetUser":            `{ GetUser(id: 1) { id } }`,
+	"Logout":             `{ Logout { id } }`,
+	"SuperAdminLogin":    `{ SuperAdminLogin(email: "a@example.com", password: "secret") { id } }`,
+	"UserLogin":          `{ UserLogin(email: "a@example.com", password: "secret") { id } }`,
+	"auditLog":           `{ auditLog { operation } }`,
+	"loginLockouts":      `{ loginLockouts { value } }`,
+	"product":            `{ product(id: 1) { id } }`,
+	"products":           `{ products { id } }`,
+	"AddAddress":         `mutation { AddAddress(city: "c", district: "d", road: "r", state: "s") { id } }`,
+	"AddAdmin":           `mutation { AddAdmin(email: "a@example.com", name: "a", password: "Secret123!") { id } }`,
+	"AddProduct":         `mutation { AddProduct(name: "p", price: 1, quantity: 1) { id } }`,
+	"AddToCart":          `mutation { AddToCart(productId: 1, quantity: 1) { productId } }`,
+	"AddToWishList":      `mutation { AddToWishList(productId: 1) { productId } }`,
+	"ChangeOrderStatus":  `mutation { ChangeOrderStatus(orderId: 1, status: PROCESSING) { orderId } }`,
+	"OrderAll":           `mutation { OrderAll { orderId } }`,
+	"RemoveAddress":      `mutation { RemoveAddress { id } }`,
+	"RemoveFromCart":     `mutation { RemoveFromCart(productId: 1) { productId } }`,
+	"RemoveFromWishlist": `mutation { RemoveFromWishlist(productId: 1) { productId } }`,
+	"UpdateQuantity":     `mutation { UpdateQuantity(id: "1", increase: true, quantity: 1) { id } }`,
+	"UserCancelOrder":    `mutation { UserCancelOrder(orderId: 1) { orderId } }`,
+	"UserSignup":         `mutation { UserSignup(email: "a@example.com", name: "a", password: "Secret123!") { id } }`,
+	"adminLogin":         `mutation { adminLogin(email: "a@example.com", password: "secret") { expiresAt } }`,
+	"clearLoginLockout":  `mutation { clearLoginLockout(kind: USER, scope: EMAIL, value: "a@example.com") }`,
+	"createAddress":      `mutation { createAddress(input: {city: "c", district: "d", road: "r", state: "s"}) { result { id } } }`,
+	"createProduct":      `mutation { createProduct(input: {name: "p", price: 1, quantity: 1}) { result { id } } }`,
+	"logout":             `mutation { logout }`,
+	"signUp":             `mutation { signUp(input: {email: "a@example.com", name: "a", password: "Secret123!"}) { result { id } } }`,
+	"superAdminLogin":    `mutation { superAdminLogin(email: "a@example.com", password: "secret") { expiresAt } }`,
+	"userLogin":          `mutation { userLogin(email: "a@example.com", password: "secret") { expiresAt } }`,
+}
+
+// loggingConn records the methods called through it.
+type loggingConn struct {
+	*mock.Conn
+	mu      sync.Mutex
+	methods []string
+}
+
+func (c *loggingConn) log(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.methods = append(c.methods, method)
+}
+
+func (c *loggingConn) called() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	methods := c.methods
+	c.methods = nil
+	return methods
+}
+
+func (c *loggingConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	c.log(method)
+	return c.Conn.Invoke(ctx, method, args, reply, opts...)
+}
+
+func (c *loggingConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	c.log(method)
+	return c.Conn.NewStream(ctx, desc, method, opts...)
+}
+
+// documentedMethods reads the table of the Fixtures doc.
+func documentedMethods(t *testing.T) map[string][]string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "fixtures.go", nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if decl, ok := n.(*ast.GenDecl); ok && decl.Tok == token.TYPE && decl.Specs[0].(*ast.TypeSpec).Name.Name == "Fixtures" {
+			doc = decl.Doc.Text()
+		}
+		return doc == ""
+	})
+	table := map[string][]string{}
+	_, rows, _ := strings.Cut(doc, "The others call")
+	for _, row := range strings.Split(rows, "\n") {
+		if fields := strings.Fields(row); strings.HasPrefix(row, "\t") && len(fields) > 1 {
+			table[fields[0]] = fields[1:]
+		}
+	}
+	if len(table) == 0 {
+		t.Fatal("no table in the Fixtures doc")
+	}
+	return table
+}
+
+// TestDocumentedMethods checks the methods the Fixtures doc lists against
+// those the root fields call.
+func TestDocumentedMethods(t *testing.T) {
+	// the orders fetched before a status change are pending
+	fixtures := &mock.Fixtures{Methods: map[string]mock.Fixture{
+		"/order.OrderService/GetOrder": {Response: []byte(`{"orderStatusId": 1}`)},
+	}}
+	conn := &loggingConn{Conn: mock.NewConn(1, fixtures)}
+	auditSink, err := audit.NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auditSink.Close()
+	gw, err := harness.Start(func(deps *graph.Deps) {
+		deps.Products = pb.NewProductServiceClient(conn)
+		deps.Users = pb.NewUserServiceClient(conn)
+		deps.Carts = pb.NewCartServiceClient(conn)
+		deps.Orders = pb.NewOrderServiceClient(conn)
+		deps.Wishlists = pb.NewWishlistServiceClient(conn)
+		deps.Lockouts = &lockout.Tracker{Store: lockout.NewMemoryStore(), Policies: lockout.DefaultPolicies()}
+		deps.Audit = &audit.Auditor{Sink: auditSink}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gw.Close()
+	session, err := harness.Session(1, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := graph.NewSchema(graph.Deps{LegacyMutations: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	documented := documentedMethods(t)
+	var roots []string
+	for name := range schema.QueryType().Fields() {
+		roots = append(roots, name)
+	}
+	for name := range schema.MutationType().Fields() {
+		roots = append(roots, name)
+	}
+	for _, name := range roots {
+		op, ok := operations[name]
+		if !ok {
+			t.Errorf("no operation calls %s", name)
+			continue
+		}
+		res, err := gw.Query(op, nil, session)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Errors) > 0 {
+			t.Errorf("%s: %s", name, res.Errors[0].Message)
+		}
+		methods := conn.called()
+		want, ok := documented[name]
+		delete(documented, name)
+		switch {
+		case ok && !reflect.DeepEqual(methods, want):
+			t.Errorf("%s calls %v, documented as %v", name, methods, want)
+		case !ok && len(methods) > 0 && (len(methods) != 1 || !strings.HasSuffix(methods[0], "/"+name)):
+			t.Errorf("%s calls %v, which the Fixtures doc does not list", name, methods)
+		}
+	}
+	for name := range documented {
+		t.Errorf("the Fixtures doc lists %s, which is not a root field", name)
+	}
+}
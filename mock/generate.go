@@ -0,0 +1,135 @@
+package mock
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxDepth stops generating nested messages.
+const maxDepth = 3
+
+var words = []string{"amber", "birch", "cedar", "delta", "ember", "fjord", "grove", "harbor", "iris", "juniper"}
+
+// generate fills every field of msg with random values.
+func (c *Conn) generate(msg protoreflect.Message, rng *rand.Rand, depth int) {
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		switch {
+		case fd.IsMap() || fd.ContainingOneof() != nil:
+			continue
+		case fd.IsList():
+			if fd.Kind() == protoreflect.MessageKind && depth >= maxDepth {
+				continue
+			}
+			list := msg.Mutable(fd).List()
+			for n := 1 + rng.Intn(3); n > 0; n-- {
+				if fd.Kind() == protoreflect.MessageKind {
+					elem := list.NewElement()
+					c.generate(elem.Message(), rng, depth+1)
+					list.Append(elem)
+				} else {
+					list.Append(c.scalar(fd, rng))
+				}
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			if depth < maxDepth {
+				c.generate(msg.Mutable(fd).Message(), rng, depth+1)
+			}
+		default:
+			msg.Set(fd, c.scalar(fd, rng))
+		}
+	}
+}
+
+func (c *Conn) scalar(fd protoreflect.FieldDescriptor, rng *rand.Rand) protoreflect.Value {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(text(fd, rng))
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(text(fd, rng)))
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(rng.Intn(2) == 0)
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		return protoreflect.ValueOfEnum(values.Get(rng.Intn(values.Len())).Number())
+	case protoreflect.FloatKind:
+		return protoreflect.ValueOfFloat32(float32(price(rng)))
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(price(rng))
+	}
+	if choices := c.Choices[fd.FullName()]; len(choices) > 0 {
+		return number(fd.Kind(), choices[rng.Intn(len(choices))])
+	}
+	return number(fd.Kind(), 1+rng.Int63n(100))
+}
+
+func price(rng *rand.Rand) float64 {
+	return math.Round(rng.Float64()*10000) / 100
+}
+
+func text(fd protoreflect.FieldDescriptor, rng *rand.Rand) string {
+	word := words[rng.Intn(len(words))]
+	name := strings.ToLower(string(fd.Name()))
+	switch {
+	case strings.Contains(name, "email"):
+		return fmt.Sprintf("%s%d@example.com", word, rng.Intn(100))
+	case strings.Contains(name, "name"):
+		return strings.ToUpper(word[:1]) + word[1:]
+	}
+	return fmt.Sprintf("%s-%d", word, rng.Intn(1000))
+}
+
+// number converts n to the integer kind of a field.
+func number(kind protoreflect.Kind, n int64) protoreflect.Value {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(int32(n))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(n)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(uint32(n))
+	}
+	return protoreflect.ValueOfUint64(uint64(n))
+}
+
+func integer(kind protoreflect.Kind) bool {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return true
+	}
+	return false
+}
+
+// echo copies the scalar fields set in the request to the fields of the
+// response with the same JSON name, so that e.g. the product returned for
+// an id has that id.
+func echo(req, res protoreflect.Message) {
+	fields := res.Descriptor().Fields()
+	req.Range(func(reqField protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		fd := fields.ByJSONName(reqField.JSONName())
+		if fd == nil || fd.IsList() || fd.IsMap() || reqField.IsList() || reqField.IsMap() {
+			return true
+		}
+		switch {
+		case fd.Kind() == reqField.Kind():
+			res.Set(fd, v)
+		case integer(fd.Kind()) && integer(reqField.Kind()):
+			var n int64
+			switch reqField.Kind() {
+			case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+				n = int64(v.Uint())
+			default:
+				n = v.Int()
+			}
+			res.Set(fd, number(fd.Kind(), n))
+		}
+		return true
+	})
+}
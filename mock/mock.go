@@ -0,0 +1,131 @@
+// Package mock answers the gateway's upstream calls with generated data so
+// the schema can be served without any backend running. Responses are
+// built from the protobuf descriptors of the reply messages and are
+// deterministic for a seed and request: asking for the same product twice
+// gives the same product. Scalar request fields are echoed into the reply
+// fields of the same name, and fixtures override whole calls or single
+// fields.
+package mock
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"math/rand"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DefaultCount is the number of messages a mocked stream sends when no
+// fixture sets it.
+const DefaultCount = 3
+
+// Conn generates responses in place of a connection to the upstreams.
+type Conn struct {
+	// Choices restricts generated numbers to a set of values by field, such
+	// as the ids the gateway maps to enum values. Other numbers are in
+	// 1..100.
+	Choices map[protoreflect.FullName][]int64
+
+	seed     int64
+	fixtures *Fixtures
+}
+
+var _ grpc.ClientConnInterface = (*Conn)(nil)
+
+// NewConn returns a Conn generating from seed, fixtures may be nil.
+func NewConn(seed int64, fixtures *Fixtures) *Conn {
+	if fixtures == nil {
+		fixtures = &Fixtures{}
+	}
+	return &Conn{Choices: map[protoreflect.FullName][]int64{}, seed: seed, fixtures: fixtures}
+}
+
+// rand returns the source of the responses to method called with
+// requests, seeded from the seed and the requests themselves.
+func (c *Conn) rand(method string, requests []proto.Message) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(method))
+	for _, req := range requests {
+		data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+		h.Write(data)
+	}
+	return rand.New(rand.NewSource(c.seed ^ int64(h.Sum64())))
+}
+
+// fill generates the i-th response of a call into reply.
+func (c *Conn) fill(fixture Fixture, rng *rand.Rand, requests []proto.Message, i int, reply proto.Message) error {
+	msg := reply.ProtoReflect()
+	c.generate(msg, rng, 0)
+	if len(requests) > 0 {
+		echo(requests[0].ProtoReflect(), msg)
+	}
+	return fixture.apply(i, reply)
+}
+
+func (c *Conn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	fixture := c.fixtures.Methods[method]
+	if err := fixture.Err(); err != nil {
+		return err
+	}
+	requests := []proto.Message{args.(proto.Message)}
+	return c.fill(fixture, c.rand(method, requests), requests, 0, reply.(proto.Message))
+}
+
+// NewStream mocks a stream. The requests sent seed the responses, which
+// are generated as they are received.
+func (c *Conn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return &mockStream{ctx: ctx, conn: c, method: method, fixture: c.fixtures.Methods[method]}, nil
+}
+
+type mockStream struct {
+	ctx      context.Context
+	conn     *Conn
+	method   string
+	fixture  Fixture
+	requests []proto.Message
+	rng      *rand.Rand
+	next     int
+}
+
+func (s *mockStream) SendMsg(m interface{}) error {
+	s.requests = append(s.requests, proto.Clone(m.(proto.Message)))
+	return nil
+}
+
+func (s *mockStream) RecvMsg(m interface{}) error {
+	if err := s.ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	if err := s.fixture.Err(); err != nil {
+		return err
+	}
+	if s.rng == nil {
+		s.rng = s.conn.rand(s.method, s.requests)
+	}
+	if s.next >= s.fixture.count() {
+		return io.EOF
+	}
+	s.next++
+	return s.conn.fill(s.fixture, s.rng, s.requests, s.next-1, m.(proto.Message))
+}
+
+func (s *mockStream) Header() (metadata.MD, error) {
+	return metadata.MD{}, nil
+}
+
+func (s *mockStream) Trailer() metadata.MD {
+	return metadata.MD{}
+}
+
+func (s *mockStream) CloseSend() error {
+	return nil
+}
+
+func (s *mockStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,164 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vishnusunil243/api_gateway/authorize"
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func getProduct(t *testing.T, conn *Conn, id int32) *pb.AddProductResponse {
+	t.Helper()
+	res, err := pb.NewProductServiceClient(conn).GetProduct(context.Background(), &pb.GetProductById{Id: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestSeed(t *testing.T) {
+	first := getProduct(t, NewConn(1, nil), 5)
+	if !proto.Equal(first, getProduct(t, NewConn(1, nil), 5)) {
+		t.Error("a seed generated different responses to one request")
+	}
+	if proto.Equal(first, getProduct(t, NewConn(2, nil), 5)) {
+		t.Error("two seeds generated the same response")
+	}
+	if first.Id != 5 {
+		t.Errorf("requested id not echoed, got %d", first.Id)
+	}
+	if first.Name == "" || first.Price < 1 || first.Price > 100 {
+		t.Errorf("generated %v", first)
+	}
+}
+
+func TestChoices(t *testing.T) {
+	conn := NewConn(1, nil)
+	conn.Choices["order.GetAllOrderResponse.order_status_id"] = []int64{7, 9}
+	stream, err := pb.NewOrderServiceClient(conn).GetAllOrders(context.Background(), &pb.NoParam{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < DefaultCount; i++ {
+		res, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.OrderStatusId != 7 && res.OrderStatusId != 9 {
+			t.Errorf("generated status id %d", res.OrderStatusId)
+		}
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	var fixtures Fixtures
+	if err := json.Unmarshal([]byte(`{"methods": {
+		"/product.ProductService/GetProduct": {"response": {"name": "Kettle", "quantity": 0}},
+		"/product.ProductService/GetAllProducts": {"response": {"price": 10}, "responses": [{"name": "a"}, {"name": "b", "price": 20}]},
+		"/order.OrderService/GetOrder": {"response": {"order_items": [{"id": 1}]}},
+		"/user.UserService/AdminLogin": {"code": 16, "message": "invalid email or password"}
+	}}`), &fixtures); err != nil {
+		t.Fatal(err)
+	}
+	conn := NewConn(1, &fixtures)
+	generated := getProduct(t, NewConn(1, nil), 5)
+	product := getProduct(t, conn, 5)
+	// zero values replace the generated ones, other fields are kept
+	if product.Name != "Kettle" || product.Quantity != 0 || product.Price != generated.Price {
+		t.Errorf("GetProduct answered %v, generated %v", product, generated)
+	}
+
+	stream, err := pb.NewProductServiceClient(conn).GetAllProducts(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var products []*pb.AddProductResponse
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		products = append(products, res)
+	}
+	if len(products) != 2 || products[0].Name != "a" || products[0].Price != 10 || products[1].Name != "b" || products[1].Price != 20 {
+		t.Errorf("GetAllProducts answered %v", products)
+	}
+
+	order, err := pb.NewOrderServiceClient(conn).GetOrder(context.Background(), &pb.OrderResponse{OrderId: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// repeated fields are replaced rather than appended to
+	if len(order.OrderItems) != 1 || order.OrderItems[0].Id != 1 {
+		t.Errorf("GetOrder answered items %v", order.OrderItems)
+	}
+
+	_, err = pb.NewUserServiceClient(conn).AdminLogin(context.Background(), &pb.UserLoginRequest{Email: "a@example.com"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("AdminLogin returned %v", err)
+	}
+}
+
+func TestStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := pb.NewProductServiceClient(NewConn(1, nil)).GetAllProducts(ctx, &emptypb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("got %v, want a canceled status", err)
+	}
+}
+
+func TestTokenHandler(t *testing.T) {
+	secret := []byte("mock")
+	for _, tc := range []struct {
+		query             string
+		code              int
+		userId            uint
+		admin, superadmin bool
+	}{
+		{"", http.StatusOK, 1, false, false},
+		{"?userId=7&role=user", http.StatusOK, 7, false, false},
+		{"?role=admin", http.StatusOK, 1, true, false},
+		{"?role=superadmin", http.StatusOK, 1, true, true},
+		{"?role=root", http.StatusBadRequest, 0, false, false},
+		{"?userId=0", http.StatusBadRequest, 0, false, false},
+		{"?userId=x", http.StatusBadRequest, 0, false, false},
+	} {
+		w := httptest.NewRecorder()
+		TokenHandler(secret).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dev/token"+tc.query, nil))
+		if w.Code != tc.code {
+			t.Errorf("%s answered %d", tc.query, w.Code)
+			continue
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		var body struct {
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		claims, err := authorize.ValidateToken(body.Token, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claims["userId"] != tc.userId || claims["isadmin"] != tc.admin || claims["isuadmin"] != tc.superadmin {
+			t.Errorf("%s issued %v", tc.query, claims)
+		}
+		if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].Value != body.Token {
+			t.Errorf("%s set cookies %v", tc.query, cookies)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package mock
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vishnusunil243/api_gateway/authorize"
+)
+
+// NewSecret returns a random secret for the tokens of a mocked gateway,
+// which must not be signed with the secret of a real one.
+func NewSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// TokenHandler issues session tokens signed with secret without a login,
+// so that mocked sessions still go through the auth middlewares:
+//
+//	GET /dev/token?userId=7&role=admin
+//
+// role is user (the default), admin or superadmin. The token is set as
+// the session cookie and returned in the body for clients that send it
+// themselves. It must never be mounted outside mock mode, and secret must
+// come from NewSecret.
+func TokenHandler(secret []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId := uint64(1)
+		if v := r.URL.Query().Get("userId"); v != "" {
+			var err error
+			if userId, err = strconv.ParseUint(v, 10, 32); err != nil || userId < 1 {
+				http.Error(w, "invalid userId", http.StatusBadRequest)
+				return
+			}
+		}
+		var admin, superadmin bool
+		switch r.URL.Query().Get("role") {
+		case "", "user":
+		case "admin":
+			admin = true
+		case "superadmin":
+			admin, superadmin = true, true
+		default:
+			http.Error(w, "role must be user, admin or superadmin", http.StatusBadRequest)
+			return
+		}
+		token, expiresAt, err := authorize.IssueJwt(uint(userId), admin, superadmin, secret)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     "jwtToken",
+			Value:    token,
+			Path:     "/",
+			MaxAge:   int(time.Until(expiresAt).Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":     token,
+			"expiresAt": expiresAt,
+		})
+	})
+}
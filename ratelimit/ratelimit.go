@@ -0,0 +1,303 @@
+// Package ratelimit throttles clients with token buckets keyed by client IP,
+// authenticated user and operation.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/identity"
+	"github.com/vishnusunil243/api_gateway/logging"
+	"github.com/vishnusunil243/api_gateway/metrics"
+)
+
+const (
+	ScopeIP        = "ip"
+	ScopeUser      = "user"
+	ScopeOperation = "operation"
+)
+
+// Policy allows Burst requests at once, refilled at Rate per second.
+type Policy struct {
+	Rate  float64
+	Burst int
+}
+
+func (p Policy) enabled() bool {
+	return p.Rate > 0 && p.Burst > 0
+}
+
+// ParsePolicy reads "<count>/<s|m|h>" with an optional ":<burst>", e.g.
+// "5/m:5". The burst defaults to the count.
+func ParsePolicy(v string) (Policy, error) {
+	limit, burst, hasBurst := strings.Cut(v, ":")
+	count, per, ok := strings.Cut(limit, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("invalid rate limit %q", v)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit %q", v)
+	}
+	var period time.Duration
+	switch per {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		return Policy{}, fmt.Errorf("invalid rate limit period %q", per)
+	}
+	p := Policy{Rate: float64(n) / period.Seconds(), Burst: n}
+	if hasBurst {
+		if p.Burst, err = strconv.Atoi(burst); err != nil || p.Burst < 0 {
+			return Policy{}, fmt.Errorf("invalid rate limit burst %q", v)
+		}
+	}
+	return p, nil
+}
+
+// Policies configures the limiter. A zero policy disables that limit.
+type Policies struct {
+	// IP applies to every HTTP request from a client address.
+	IP Policy
+	// User applies to every limited operation called by an authenticated user.
+	User Policy
+	// Operations apply per caller, the user when authenticated and the
+	// client address otherwise.
+	Operations map[string]Policy
+}
+
+func DefaultPolicies() Policies {
+	return Policies{
+		IP:   Policy{Rate: 20, Burst: 40},
+		User: Policy{Rate: 10, Burst: 20},
+		Operations: map[string]Policy{
+			"UserLogin":       {Rate: 5.0 / 60, Burst: 5},
+			"AdminLogin":      {Rate: 3.0 / 60, Burst: 3},
+			"SuperAdminLogin": {Rate: 3.0 / 60, Burst: 3},
+			"GetAllUsers":     {Rate: 1, Burst: 5},
+			"GetAllAdmins":    {Rate: 1, Burst: 5},
+			"GetAllOrders":    {Rate: 1, Burst: 5},
+			"auditLog":        {Rate: 1, Burst: 5},
+		},
+	}
+}
+
+// Limiter enforces the policies of one gateway. A nil *Limiter lets
+// everything through.
+type Limiter struct {
+	Store    Store
+	Policies Policies
+	// TrustProxy takes the client address from the last X-Forwarded-For
+	// entry. Only enable it behind a single proxy that appends to the header.
+	TrustProxy bool
+
+	mu         sync.Mutex
+	operations map[string]bool
+}
+
+// Error is returned by limited resolvers.
+type Error struct {
+	Scope      string
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("too many requests, retry after %ds", retrySeconds(e.RetryAfter))
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":       "RATE_LIMITED",
+		"scope":      e.Scope,
+		"retryAfter": retrySeconds(e.RetryAfter),
+	}
+}
+
+func retrySeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// take reports a nil error when the request is allowed. Store failures are
+// logged and let the request through rather than taking the gateway down.
+func (l *Limiter) take(ctx context.Context, scope, key string, p Policy) *Error {
+	if !p.enabled() {
+		return nil
+	}
+	ok, wait, err := l.Store.Take(ctx, scope+":"+key, p)
+	if err != nil {
+		logging.FromContext(ctx).Error("rate limit store failed", "scope", scope, "error", err.Error())
+		return nil
+	}
+	if ok {
+		return nil
+	}
+	metrics.RateLimited.WithLabelValues(scope).Inc()
+	return &Error{Scope: scope, RetryAfter: wait}
+}
+
+// Middleware applies the IP policy to every request, answering 429 with a
+// Retry-After header when it is exceeded.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := l.take(r.Context(), ScopeIP, l.ClientIP(r), l.Policies.IP); err != nil {
+			w.Header().Set("Retry-After", strconv.Itoa(retrySeconds(err.RetryAfter)))
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Operation limits next per caller under the operation's policy and, for
+// authenticated callers, the user policy. Wrapped by an auth middleware it
+// limits by user, otherwise by client address. A rejected call sets
+// Retry-After on the HTTP response.
+func (l *Limiter) Operation(name string, next graphql.FieldResolveFn) graphql.FieldResolveFn {
+	if l == nil {
+		return next
+	}
+	l.mu.Lock()
+	if l.operations == nil {
+		l.operations = map[string]bool{}
+	}
+	l.operations[name] = true
+	l.mu.Unlock()
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		r, _ := p.Context.Value("request").(*http.Request)
+		caller := ""
+		if id, ok := identity.FromContext(p.Context); ok {
+			caller = "user:" + strconv.FormatUint(uint64(id.UserID), 10)
+			if err := l.take(p.Context, ScopeUser, caller, l.Policies.User); err != nil {
+				return nil, retryAfter(p, err)
+			}
+		} else if r != nil {
+			caller = "ip:" + l.ClientIP(r)
+		}
+		if err := l.take(p.Context, ScopeOperation, name+":"+caller, l.Policies.Operations[name]); err != nil {
+			return nil, retryAfter(p, err)
+		}
+		return next(p)
+	}
+}
+
+// Check returns an error for an operation policy no resolver is wrapped
+// under, so a misspelled override does not go unnoticed. Call it once the
+// schema is built; the operations of DefaultPolicies are always accepted
+// since their module may be disabled.
+func (l *Limiter) Check() error {
+	if l == nil {
+		return nil
+	}
+	defaults := DefaultPolicies().Operations
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var unknown []string
+	for name := range l.Policies.Operations {
+		if _, ok := defaults[name]; !ok && !l.operations[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("RATE_LIMIT_OPERATIONS names operations that are not rate limited: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func retryAfter(p graphql.ResolveParams, err *Error) error {
+	if w, ok := p.Context.Value("httpResponseWriter").(http.ResponseWriter); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(retrySeconds(err.RetryAfter)))
+	}
+	return err
+}
+
+// ClientIP returns the address of the client that sent r.
+func (l *Limiter) ClientIP(r *http.Request) string {
+	return ClientIP(r, l.TrustProxy)
+}
+
+// ClientIP returns the address of the client that sent r, taken from
+// X-Forwarded-For when trustProxy is set. Only the last entry, appended by
+// the trusted proxy, is used; the ones before it come from the client and
+// may be forged.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			forwarded := values[len(values)-1]
+			if i := strings.LastIndex(forwarded, ","); i >= 0 {
+				forwarded = forwarded[i+1:]
+			}
+			if forwarded = strings.TrimSpace(forwarded); forwarded != "" {
+				return forwarded
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// LoadConfig builds the limiter settings from the environment, starting
+// from DefaultPolicies:
+//
+//	RATE_LIMIT_IP, RATE_LIMIT_USER  policies as accepted by ParsePolicy, "off" disables
+//	RATE_LIMIT_OPERATIONS           comma separated <operation>=<policy> overrides, see Limiter.Check
+//	RATE_LIMIT_TRUST_PROXY          "true" to use X-Forwarded-For
+func LoadConfig() (Policies, bool, error) {
+	policies := DefaultPolicies()
+	var err error
+	if policies.IP, err = envPolicy("RATE_LIMIT_IP", policies.IP); err != nil {
+		return Policies{}, false, err
+	}
+	if policies.User, err = envPolicy("RATE_LIMIT_USER", policies.User); err != nil {
+		return Policies{}, false, err
+	}
+	if v := os.Getenv("RATE_LIMIT_OPERATIONS"); v != "" {
+		for _, entry := range strings.Split(v, ",") {
+			name, policy, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			if !ok {
+				return Policies{}, false, fmt.Errorf("invalid RATE_LIMIT_OPERATIONS entry %q", entry)
+			}
+			p, err := parseEnvPolicy(policy)
+			if err != nil {
+				return Policies{}, false, err
+			}
+			policies.Operations[name] = p
+		}
+	}
+	return policies, os.Getenv("RATE_LIMIT_TRUST_PROXY") == "true", nil
+}
+
+func envPolicy(key string, def Policy) (Policy, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	return parseEnvPolicy(v)
+}
+
+func parseEnvPolicy(v string) (Policy, error) {
+	if v == "off" {
+		return Policy{}, nil
+	}
+	return ParsePolicy(v)
+}
@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	for _, tc := range []struct {
+		forwarded  []string
+		trustProxy bool
+		want       string
+	}{
+		{nil, true, "192.0.2.1"},
+		{[]string{"198.51.100.7"}, false, "192.0.2.1"},
+		{[]string{"198.51.100.7"}, true, "198.51.100.7"},
+		{[]string{"203.0.113.9, 198.51.100.7"}, true, "198.51.100.7"},
+		{[]string{"203.0.113.9", "198.51.100.7"}, true, "198.51.100.7"},
+		{[]string{" "}, true, "192.0.2.1"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		for _, v := range tc.forwarded {
+			r.Header.Add("X-Forwarded-For", v)
+		}
+		if got := ClientIP(r, tc.trustProxy); got != tc.want {
+			t.Errorf("ClientIP(%q, %t) = %q, want %q", tc.forwarded, tc.trustProxy, got, tc.want)
+		}
+	}
+}
+
+func TestMiddlewareForgedForwardedFor(t *testing.T) {
+	l := &Limiter{Store: NewMemoryStore(), Policies: Policies{IP: Policy{Rate: 0.001, Burst: 2}}, TrustProxy: true}
+	h := l.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	forged := []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"}
+	for i, leftmost := range forged {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Forwarded-For", leftmost+", 198.51.100.7")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		want := http.StatusOK
+		if i >= 2 {
+			want = http.StatusTooManyRequests
+		}
+		if w.Code != want {
+			t.Errorf("request %d from %s answered %d, want %d", i, leftmost, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Store holds token buckets. Take must refill and consume atomically so that
+// a shared implementation (e.g. a Redis script) enforces one limit across
+// all gateway instances.
+type Store interface {
+	// Take removes one token from the bucket for key. When the bucket is
+	// empty it reports how long until a token is available.
+	Take(ctx context.Context, key string, p Policy) (bool, time.Duration, error)
+}
+
+// MemoryStore keeps buckets in process, so limits apply per gateway instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	takes   int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	policy Policy
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, p Policy) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.takes++
+	if s.takes%1000 == 0 {
+		s.sweep(now)
+	}
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(p.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.policy = p
+	b.tokens = math.Min(float64(p.Burst), b.tokens+now.Sub(b.last).Seconds()*p.Rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	wait := time.Duration((1 - b.tokens) / p.Rate * float64(time.Second))
+	return false, wait, nil
+}
+
+// sweep drops buckets that have refilled completely, they are the same as
+// a missing bucket.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.policy.Rate >= float64(b.policy.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
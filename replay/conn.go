@@ -0,0 +1,143 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Conn serves recorded calls in place of a connection to the upstreams. A
+// call is answered by the first recording of the same method with equal
+// requests that has not been replayed yet, or by the last one replayed
+// once they are used up, so a session repeating a request sees the
+// responses in the order they were recorded.
+type Conn struct {
+	mu    sync.Mutex
+	calls []*Call
+	used  []bool
+}
+
+var _ grpc.ClientConnInterface = (*Conn)(nil)
+
+func NewConn(calls []*Call) *Conn {
+	return &Conn{calls: calls, used: make([]bool, len(calls))}
+}
+
+// find returns the recording answering method called with requests.
+func (c *Conn) find(method string, requests []interface{}) (*Call, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	last := -1
+	for i, call := range c.calls {
+		if call.Method != method || !sameRequests(call.Requests, requests) {
+			continue
+		}
+		if !c.used[i] {
+			c.used[i] = true
+			return call, nil
+		}
+		last = i
+	}
+	if last < 0 {
+		return nil, status.Errorf(codes.Unavailable, "replay: no recorded call of %s with these requests", method)
+	}
+	return c.calls[last], nil
+}
+
+// sameRequests compares messages rather than their JSON, whose formatting
+// protojson does not keep stable.
+func sameRequests(recorded []json.RawMessage, requests []interface{}) bool {
+	if len(recorded) != len(requests) {
+		return false
+	}
+	for i, req := range requests {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return false
+		}
+		want := msg.ProtoReflect().New().Interface()
+		if err := unmarshal(recorded[i], want); err != nil || !proto.Equal(want, msg) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Conn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	call, err := c.find(method, []interface{}{args})
+	if err != nil {
+		return err
+	}
+	if err := call.Err(); err != nil {
+		return err
+	}
+	if len(call.Responses) == 0 {
+		return status.Errorf(codes.Internal, "replay: recorded call of %s has no response", method)
+	}
+	return unmarshal(call.Responses[0], reply)
+}
+
+// NewStream replays a stream. The requests sent are matched once the
+// first message is received, the recorded responses are then delivered
+// followed by the recorded status.
+func (c *Conn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return &replayStream{ctx: ctx, conn: c, method: method}, nil
+}
+
+type replayStream struct {
+	ctx      context.Context
+	conn     *Conn
+	method   string
+	requests []interface{}
+	call     *Call
+	next     int
+}
+
+func (s *replayStream) SendMsg(m interface{}) error {
+	s.requests = append(s.requests, proto.Clone(m.(proto.Message)))
+	return nil
+}
+
+func (s *replayStream) RecvMsg(m interface{}) error {
+	if err := s.ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	if s.call == nil {
+		call, err := s.conn.find(s.method, s.requests)
+		if err != nil {
+			return err
+		}
+		s.call = call
+	}
+	if s.next < len(s.call.Responses) {
+		s.next++
+		return unmarshal(s.call.Responses[s.next-1], m)
+	}
+	if err := s.call.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+func (s *replayStream) Header() (metadata.MD, error) {
+	return metadata.MD{}, nil
+}
+
+func (s *replayStream) Trailer() metadata.MD {
+	return metadata.MD{}
+}
+
+func (s *replayStream) CloseSend() error {
+	return nil
+}
+
+func (s *replayStream) Context() context.Context {
+	return s.ctx
+}
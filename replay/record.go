@@ -0,0 +1,112 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vishnusunil243/api_gateway/clientstream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Recorder appends every call made through its interceptors to a file, one
+// JSON encoded Call per line.
+type Recorder struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func NewRecorder(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (r *Recorder) Close() error {
+	return r.file.Close()
+}
+
+func (r *Recorder) write(call *Call, err error) {
+	s := status.Convert(err)
+	call.Code = s.Code()
+	call.Message = s.Message()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// a lost recording must not fail the call it describes
+	_ = r.enc.Encode(call)
+}
+
+// UnaryClientInterceptor records the unary calls made to the named upstream.
+func (r *Recorder) UnaryClientInterceptor(upstream string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		call := &Call{Time: time.Now().UTC(), Upstream: upstream, Method: method}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if data, marshalErr := marshal(req); marshalErr == nil {
+			call.Requests = append(call.Requests, data)
+		}
+		if err == nil {
+			if data, marshalErr := marshal(reply); marshalErr == nil {
+				call.Responses = append(call.Responses, data)
+			}
+		}
+		r.write(call, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor records streams once they are drained, fail or are
+// abandoned with their context.
+func (r *Recorder) StreamClientInterceptor(upstream string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		call := &Call{Time: time.Now().UTC(), Upstream: upstream, Method: method}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			r.write(call, err)
+			return nil, err
+		}
+		rs := &recordingStream{ClientStream: stream, recorder: r, call: call}
+		return clientstream.Watch(rs, rs.done), nil
+	}
+}
+
+type recordingStream struct {
+	grpc.ClientStream
+	recorder *Recorder
+	mu       sync.Mutex
+	call     *Call
+}
+
+func (s *recordingStream) SendMsg(m interface{}) error {
+	if data, err := marshal(m); err == nil {
+		s.mu.Lock()
+		s.call.Requests = append(s.call.Requests, data)
+		s.mu.Unlock()
+	}
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *recordingStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		if data, marshalErr := marshal(m); marshalErr == nil {
+			s.mu.Lock()
+			s.call.Responses = append(s.call.Responses, data)
+			s.mu.Unlock()
+		}
+	}
+	return err
+}
+
+// done writes the recording with the responses received until the stream
+// ended.
+func (s *recordingStream) done(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recorder.write(s.call, err)
+}
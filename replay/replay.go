@@ -0,0 +1,79 @@
+// Package replay records the gRPC traffic between the gateway and its
+// upstreams to a file and serves it back without dialing them, so that a
+// captured GraphQL session can be re-run locally.
+//
+// Recordings hold request and response messages as they were sent,
+// passwords included, and must be handled like production data.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Call is one recorded RPC. Unary calls have a single request and at most
+// one response, streams every message sent and received in order.
+type Call struct {
+	Time      time.Time         `json:"time"`
+	Upstream  string            `json:"upstream"`
+	Method    string            `json:"method"`
+	Requests  []json.RawMessage `json:"requests"`
+	Responses []json.RawMessage `json:"responses"`
+	Code      codes.Code        `json:"code"`
+	Message   string            `json:"message,omitempty"`
+}
+
+// Err is the status the call ended with.
+func (c *Call) Err() error {
+	if c.Code == codes.OK {
+		return nil
+	}
+	return status.Error(c.Code, c.Message)
+}
+
+func marshal(m interface{}) (json.RawMessage, error) {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("cannot record %T, it is not a protobuf message", m)
+	}
+	return protojson.Marshal(msg)
+}
+
+func unmarshal(data json.RawMessage, m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("cannot replay into %T, it is not a protobuf message", m)
+	}
+	return protojson.Unmarshal(data, msg)
+}
+
+// Load reads the calls recorded to path.
+func Load(path string) ([]*Call, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var calls []*Call
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		call := &Call{}
+		if err := json.Unmarshal(scanner.Bytes(), call); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", path, line, err.Error())
+		}
+		calls = append(calls, call)
+	}
+	return calls, scanner.Err()
+}
@@ -0,0 +1,124 @@
+package replay
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/vishnusunil243/api_gateway/fakes"
+	"github.com/vishnusunil243/proto-files/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// result is what one call returned, compared between recording and replay.
+type result struct {
+	responses []*pb.AddProductResponse
+	code      codes.Code
+}
+
+func (r result) equal(other result) bool {
+	if r.code != other.code || len(r.responses) != len(other.responses) {
+		return false
+	}
+	for i := range r.responses {
+		if !proto.Equal(r.responses[i], other.responses[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func getProduct(client pb.ProductServiceClient, id int32) result {
+	res, err := client.GetProduct(context.Background(), &pb.GetProductById{Id: id})
+	if err != nil {
+		return result{code: status.Code(err)}
+	}
+	return result{responses: []*pb.AddProductResponse{res}}
+}
+
+func getAllProducts(client pb.ProductServiceClient) result {
+	stream, err := client.GetAllProducts(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		return result{code: status.Code(err)}
+	}
+	var r result
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return r
+		}
+		if err != nil {
+			r.code = status.Code(err)
+			return r
+		}
+		r.responses = append(r.responses, res)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	backends := fakes.Start()
+	defer backends.Close()
+	path := filepath.Join(t.TempDir(), "calls.jsonl")
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, err := backends.Dial(
+		grpc.WithChainUnaryInterceptor(recorder.UnaryClientInterceptor("product")),
+		grpc.WithChainStreamInterceptor(recorder.StreamClientInterceptor("product")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	live := pb.NewProductServiceClient(cc)
+
+	first := backends.Products.Add("lamp", 1500, 4)
+	backends.Products.Add("desk", 9000, 1)
+	var recorded []result
+	// the same request twice, answered differently as the stock changes
+	recorded = append(recorded, getProduct(live, int32(first.Id)))
+	if _, err := live.UpdateQuantity(context.Background(), &pb.UpdateQuantityRequest{Id: first.Id, Quantity: 3}); err != nil {
+		t.Fatal(err)
+	}
+	recorded = append(recorded, getProduct(live, int32(first.Id)))
+	recorded = append(recorded, getProduct(live, 99))
+	recorded = append(recorded, getAllProducts(live))
+	backends.Fail("/product.ProductService/GetAllProducts", status.Error(codes.Unavailable, "down"))
+	recorded = append(recorded, getAllProducts(live))
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if recorded[0].equal(recorded[1]) || recorded[2].code != codes.NotFound || len(recorded[3].responses) != 2 || recorded[4].code != codes.Unavailable {
+		t.Fatalf("unexpected live results %+v", recorded)
+	}
+
+	calls, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := pb.NewProductServiceClient(NewConn(calls))
+	for i, got := range []result{
+		getProduct(replayed, int32(first.Id)),
+		getProduct(replayed, int32(first.Id)),
+		getProduct(replayed, 99),
+		getAllProducts(replayed),
+		getAllProducts(replayed),
+	} {
+		if !got.equal(recorded[i]) {
+			t.Errorf("call %d replayed %+v, recorded %+v", i, got, recorded[i])
+		}
+	}
+	// once the recordings are used up the last one is replayed again
+	if got := getProduct(replayed, int32(first.Id)); !got.equal(recorded[1]) {
+		t.Errorf("repeated call replayed %+v, want the last recording", got)
+	}
+	if got := getProduct(replayed, 7); got.code != codes.Unavailable {
+		t.Errorf("unrecorded call answered %+v", got)
+	}
+}
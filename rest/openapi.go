@@ -0,0 +1,293 @@
+package rest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// OpenAPI describes the routes as an OpenAPI 3 document. Parameter, body and
+// response schemas are derived from the operations' variables and
+// selections, so the document follows the schema without extra upkeep.
+func (f *Facade) OpenAPI(title, version string) map[string]interface{} {
+	paths := map[string]interface{}{}
+	for _, rt := range f.routes {
+		item, _ := paths[rt.Path].(map[string]interface{})
+		if item == nil {
+			item = map[string]interface{}{}
+			paths[rt.Path] = item
+		}
+		item[strings.ToLower(rt.Method)] = f.operation(rt)
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				"session": map[string]interface{}{
+					"type": "apiKey",
+					"in":   "cookie",
+					"name": "jwtToken",
+				},
+			},
+			"schemas": map[string]interface{}{
+				"Errors": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"errors": map[string]interface{}{
+							"type": "array",
+							"items": map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"message":    map[string]interface{}{"type": "string"},
+									"path":       map[string]interface{}{"type": "array", "items": map[string]interface{}{}},
+									"extensions": map[string]interface{}{"type": "object"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (f *Facade) operation(rt *route) map[string]interface{} {
+	id := rt.name
+	if id == "" {
+		id = strings.ToLower(rt.Method) + operationName(rt.Path)
+	}
+	op := map[string]interface{}{
+		"operationId": id,
+		"summary":     rt.Summary,
+	}
+	if len(rt.Tags) > 0 {
+		op["tags"] = rt.Tags
+	}
+	if rt.Auth {
+		op["security"] = []map[string][]string{{"session": {}}}
+	}
+	inPath := map[string]bool{}
+	var params []map[string]interface{}
+	for _, name := range rt.pathParams() {
+		inPath[name] = true
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   inputSchema(f.typeFromAST(rt.variables[name])),
+		})
+	}
+	bodyProps := map[string]interface{}{}
+	var bodyRequired []string
+	for _, name := range sortedKeys(rt.variables) {
+		if inPath[name] || name == rt.Body {
+			continue
+		}
+		t := f.typeFromAST(rt.variables[name])
+		_, required := t.(*graphql.NonNull)
+		if rt.Method == http.MethodGet || rt.Method == http.MethodDelete {
+			params = append(params, map[string]interface{}{
+				"name":     name,
+				"in":       "query",
+				"required": required,
+				"schema":   inputSchema(t),
+			})
+			continue
+		}
+		bodyProps[name] = inputSchema(t)
+		if required {
+			bodyRequired = append(bodyRequired, name)
+		}
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	var body map[string]interface{}
+	if rt.Body != "" {
+		body = inputSchema(f.typeFromAST(rt.variables[rt.Body]))
+	} else if len(bodyProps) > 0 {
+		body = map[string]interface{}{"type": "object", "properties": bodyProps}
+		if len(bodyRequired) > 0 {
+			body["required"] = bodyRequired
+		}
+	}
+	if body != nil {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": body}},
+		}
+	}
+	code := rt.Status
+	if code == 0 {
+		code = http.StatusOK
+	}
+	errorsRef := map[string]interface{}{
+		"description": "Error",
+		"content": map[string]interface{}{"application/json": map[string]interface{}{
+			"schema": map[string]interface{}{"$ref": "#/components/schemas/Errors"},
+		}},
+	}
+	field := rt.parent.Fields()[rt.selection.Name.Value]
+	op["responses"] = map[string]interface{}{
+		strconv.Itoa(code): map[string]interface{}{
+			"description": http.StatusText(code),
+			"content": map[string]interface{}{"application/json": map[string]interface{}{
+				"schema": outputSchema(resultOf(field.Type, rt.selection.SelectionSet)),
+			}},
+		},
+		"default": errorsRef,
+	}
+	return op
+}
+
+// resultOf unwraps the { result, userErrors } payloads, whose result is
+// what the REST response carries.
+func resultOf(t graphql.Output, selections *ast.SelectionSet) (graphql.Output, *ast.SelectionSet) {
+	object, ok := unwrapOutput(t).(*graphql.Object)
+	if !ok {
+		return t, selections
+	}
+	fields := object.Fields()
+	if len(fields) != 2 || fields["result"] == nil || fields["userErrors"] == nil {
+		return t, selections
+	}
+	for _, sel := range selections.Selections {
+		if field, ok := sel.(*ast.Field); ok && field.Name.Value == "result" {
+			return fields["result"].Type, field.SelectionSet
+		}
+	}
+	return fields["result"].Type, nil
+}
+
+func unwrapOutput(t graphql.Output) graphql.Output {
+	for {
+		switch w := t.(type) {
+		case *graphql.NonNull:
+			t = w.OfType
+		case *graphql.List:
+			t = w.OfType
+		default:
+			return t
+		}
+	}
+}
+
+func (f *Facade) typeFromAST(t ast.Type) graphql.Type {
+	switch t := t.(type) {
+	case *ast.NonNull:
+		return graphql.NewNonNull(f.typeFromAST(t.Type))
+	case *ast.List:
+		return graphql.NewList(f.typeFromAST(t.Type))
+	case *ast.Named:
+		return f.schema.Type(t.Name.Value)
+	}
+	return nil
+}
+
+func inputSchema(t graphql.Type) map[string]interface{} {
+	switch t := t.(type) {
+	case *graphql.NonNull:
+		return inputSchema(t.OfType)
+	case *graphql.List:
+		return map[string]interface{}{"type": "array", "items": inputSchema(t.OfType)}
+	case *graphql.InputObject:
+		props := map[string]interface{}{}
+		var required []string
+		for name, field := range t.Fields() {
+			props[name] = inputSchema(field.Type)
+			if _, ok := field.Type.(*graphql.NonNull); ok {
+				required = append(required, name)
+			}
+		}
+		s := map[string]interface{}{"type": "object", "properties": props}
+		if len(required) > 0 {
+			sort.Strings(required)
+			s["required"] = required
+		}
+		return s
+	}
+	return leafSchema(t)
+}
+
+// outputSchema describes the selected part of t.
+func outputSchema(t graphql.Type, selections *ast.SelectionSet) map[string]interface{} {
+	switch t := t.(type) {
+	case *graphql.NonNull:
+		return outputSchema(t.OfType, selections)
+	case *graphql.List:
+		return map[string]interface{}{"type": "array", "items": outputSchema(t.OfType, selections)}
+	case *graphql.Object:
+		props := map[string]interface{}{}
+		if selections != nil {
+			for _, sel := range selections.Selections {
+				field, ok := sel.(*ast.Field)
+				if !ok {
+					continue
+				}
+				def, ok := t.Fields()[field.Name.Value]
+				if !ok {
+					continue
+				}
+				name := field.Name.Value
+				if field.Alias != nil {
+					name = field.Alias.Value
+				}
+				props[name] = outputSchema(def.Type, field.SelectionSet)
+			}
+		}
+		return map[string]interface{}{"type": "object", "properties": props}
+	}
+	return leafSchema(t)
+}
+
+func leafSchema(t graphql.Type) map[string]interface{} {
+	switch t {
+	case graphql.Int:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case graphql.Float:
+		return map[string]interface{}{"type": "number"}
+	case graphql.Boolean:
+		return map[string]interface{}{"type": "boolean"}
+	case graphql.DateTime:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	if enum, ok := t.(*graphql.Enum); ok {
+		var values []string
+		for _, v := range enum.Values() {
+			values = append(values, v.Name)
+		}
+		sort.Strings(values)
+		return map[string]interface{}{"type": "string", "enum": values}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+func operationName(path string) string {
+	var b strings.Builder
+	for _, s := range strings.Split(strings.Trim(path, "/"), "/") {
+		s = strings.Trim(s, "{}")
+		if s == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(s[:1]) + s[1:])
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string]ast.Type) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,367 @@
+// Package rest exposes named GraphQL operations as REST endpoints for
+// clients that cannot speak GraphQL. Every route is a persisted operation
+// selecting a single root field; path, query and body values become its
+// variables and the root field's value becomes the response body. Calls go
+// through the same schema, so auth, validation, rate limits and audit apply
+// unchanged.
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxBodySize bounds the JSON body of a request.
+const MaxBodySize = 1 << 20
+
+type Route struct {
+	Method  string
+	Path    string
+	Summary string
+	Tags    []string
+	// Module is the schema module whose fields Operation selects, the
+	// route is not served when it is disabled.
+	Module string
+	// Operation is the persisted GraphQL document. Its variables are filled
+	// from path parameters, then the body, then the query string.
+	Operation string
+	// Body names the variable that receives the whole JSON body. When empty
+	// the body must be an object whose keys are variable names.
+	Body string
+	// Status is the response status on success, 200 when unset.
+	Status int
+	// Auth marks routes that need the session cookie, for the OpenAPI
+	// document only; the resolvers enforce it.
+	Auth bool
+}
+
+type route struct {
+	Route
+	segments  []string
+	variables map[string]ast.Type
+	name      string
+	field     string
+	selection *ast.Field
+	parent    *graphql.Object
+}
+
+// Facade serves the routes against a schema.
+type Facade struct {
+	schema *graphql.Schema
+	routes []*route
+}
+
+// Operations returns the names of the routes' operations.
+func (f *Facade) Operations() []string {
+	var names []string
+	for _, r := range f.routes {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+// New checks every route's operation against the schema.
+func New(schema *graphql.Schema, routes []Route) (*Facade, error) {
+	f := &Facade{schema: schema}
+	for _, r := range routes {
+		compiled, err := compile(schema, r)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %s", r.Method, r.Path, err.Error())
+		}
+		f.routes = append(f.routes, compiled)
+	}
+	return f, nil
+}
+
+func compile(schema *graphql.Schema, r Route) (*route, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: r.Operation})
+	if err != nil {
+		return nil, err
+	}
+	if res := graphql.ValidateDocument(schema, doc, nil); !res.IsValid {
+		return nil, res.Errors[0]
+	}
+	if len(doc.Definitions) != 1 {
+		return nil, fmt.Errorf("operation must contain exactly one definition")
+	}
+	op, ok := doc.Definitions[0].(*ast.OperationDefinition)
+	if !ok || len(op.SelectionSet.Selections) != 1 {
+		return nil, fmt.Errorf("operation must select exactly one root field")
+	}
+	field, ok := op.SelectionSet.Selections[0].(*ast.Field)
+	if !ok {
+		return nil, fmt.Errorf("operation must select exactly one root field")
+	}
+	compiled := &route{
+		Route:     r,
+		segments:  strings.Split(strings.Trim(r.Path, "/"), "/"),
+		variables: map[string]ast.Type{},
+		field:     field.Name.Value,
+		selection: field,
+		parent:    schema.QueryType(),
+	}
+	if field.Alias != nil {
+		compiled.field = field.Alias.Value
+	}
+	if op.Name != nil {
+		compiled.name = op.Name.Value
+	}
+	if op.Operation == ast.OperationTypeMutation {
+		compiled.parent = schema.MutationType()
+	}
+	for _, v := range op.VariableDefinitions {
+		compiled.variables[v.Variable.Name.Value] = v.Type
+	}
+	for _, name := range compiled.pathParams() {
+		if _, ok := compiled.variables[name]; !ok {
+			return nil, fmt.Errorf("path parameter %s is not a variable of the operation", name)
+		}
+	}
+	if r.Body != "" {
+		if _, ok := compiled.variables[r.Body]; !ok {
+			return nil, fmt.Errorf("body variable %s is not a variable of the operation", r.Body)
+		}
+	}
+	return compiled, nil
+}
+
+func (r *route) pathParams() []string {
+	var params []string
+	for _, s := range r.segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			params = append(params, s[1:len(s)-1])
+		}
+	}
+	return params
+}
+
+// match returns the path parameters when path belongs to the route.
+func (r *route) match(path []string) (map[string]string, bool) {
+	if len(path) != len(r.segments) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, s := range r.segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			params[s[1:len(s)-1]] = path[i]
+		} else if s != path[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (f *Facade) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	allowed := false
+	for _, rt := range f.routes {
+		params, ok := rt.match(path)
+		if !ok {
+			continue
+		}
+		if rt.Method != r.Method {
+			allowed = true
+			continue
+		}
+		f.serve(w, r, rt, params)
+		return
+	}
+	if allowed {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeError(w, http.StatusNotFound, "no such endpoint")
+}
+
+func (f *Facade) serve(w http.ResponseWriter, r *http.Request, rt *route, params map[string]string) {
+	variables, err := rt.variablesFrom(r, params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx := context.WithValue(r.Context(), "httpResponseWriter", w)
+	ctx = context.WithValue(ctx, "request", r)
+	res := graphql.Do(graphql.Params{
+		Schema:         *f.schema,
+		RequestString:  rt.Operation,
+		VariableValues: variables,
+		Context:        ctx,
+	})
+	if len(res.Errors) > 0 {
+		code := statusOf(res.Errors[0])
+		if res.Data == nil {
+			// the variables did not fit the operation
+			code = http.StatusBadRequest
+		}
+		writeJSON(w, code, map[string]interface{}{"errors": res.Errors})
+		return
+	}
+	data, _ := res.Data.(map[string]interface{})
+	value := data[rt.field]
+	if payload, ok := value.(map[string]interface{}); ok {
+		if userErrors, ok := payload["userErrors"].([]interface{}); ok {
+			if len(userErrors) > 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errors": fromUserErrors(userErrors)})
+				return
+			}
+			value = payload["result"]
+		}
+	}
+	code := rt.Status
+	if code == 0 {
+		code = http.StatusOK
+	}
+	writeJSON(w, code, value)
+}
+
+func (rt *route) variablesFrom(r *http.Request, params map[string]string) (map[string]interface{}, error) {
+	variables := map[string]interface{}{}
+	for name, values := range r.URL.Query() {
+		if t, ok := rt.variables[name]; ok && len(values) > 0 {
+			v, err := fromString(name, t, values[0])
+			if err != nil {
+				return nil, err
+			}
+			variables[name] = v
+		}
+	}
+	if r.Body != nil && r.ContentLength != 0 && r.Method != http.MethodGet {
+		var body interface{}
+		if err := json.NewDecoder(io.LimitReader(r.Body, MaxBodySize)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("request body is not valid JSON")
+		}
+		if rt.Body != "" {
+			variables[rt.Body] = body
+		} else if body != nil {
+			fields, ok := body.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("request body must be a JSON object")
+			}
+			for name, v := range fields {
+				if _, ok := rt.variables[name]; ok {
+					variables[name] = v
+				}
+			}
+		}
+	}
+	for name, value := range params {
+		v, err := fromString(name, rt.variables[name], value)
+		if err != nil {
+			return nil, err
+		}
+		variables[name] = v
+	}
+	return variables, nil
+}
+
+// fromString converts path and query values to the variable's scalar type.
+func fromString(name string, t ast.Type, value string) (interface{}, error) {
+	if nonNull, ok := t.(*ast.NonNull); ok {
+		t = nonNull.Type
+	}
+	named, ok := t.(*ast.Named)
+	if !ok {
+		return value, nil
+	}
+	switch named.Name.Value {
+	case "Int":
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer", name)
+		}
+		return v, nil
+	case "Float":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", name)
+		}
+		return v, nil
+	case "Boolean":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", name)
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+func fromUserErrors(userErrors []interface{}) []map[string]interface{} {
+	res := make([]map[string]interface{}, 0, len(userErrors))
+	for _, e := range userErrors {
+		ue, _ := e.(map[string]interface{})
+		res = append(res, map[string]interface{}{
+			"message":    ue["message"],
+			"extensions": map[string]interface{}{"code": "BAD_USER_INPUT", "field": ue["field"]},
+		})
+	}
+	return res
+}
+
+var codeStatus = map[string]int{
+	"BAD_USER_INPUT":          http.StatusBadRequest,
+	"UNAUTHENTICATED":         http.StatusUnauthorized,
+	"FORBIDDEN":               http.StatusForbidden,
+	"RATE_LIMITED":            http.StatusTooManyRequests,
+	"LOGIN_LOCKED":            http.StatusTooManyRequests,
+	"IDEMPOTENCY_KEY_REUSED":  http.StatusUnprocessableEntity,
+	"IDEMPOTENCY_IN_PROGRESS": http.StatusConflict,
+	"SAGA_INCOMPLETE":         http.StatusServiceUnavailable,
+}
+
+var grpcStatus = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.Aborted:            http.StatusConflict,
+	codes.FailedPrecondition: http.StatusConflict,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+}
+
+// statusOf maps an error to an HTTP status by its "code" extension or the
+// gRPC status of the upstream error behind it.
+func statusOf(fe gqlerrors.FormattedError) int {
+	if code, ok := fe.Extensions["code"].(string); ok {
+		if s, ok := codeStatus[code]; ok {
+			return s
+		}
+	}
+	err := fe.OriginalError()
+	if gqlErr, ok := err.(*gqlerrors.Error); ok && gqlErr.OriginalError != nil {
+		err = gqlErr.OriginalError
+	}
+	if s, ok := grpcStatus[status.Code(err)]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]interface{}{
+		"errors": []gqlerrors.FormattedError{gqlerrors.NewFormattedError(message)},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
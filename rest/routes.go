@@ -0,0 +1,177 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Routes is the REST API served under /api/v1.
+var Routes = []Route{
+	{
+		Method:    http.MethodGet,
+		Path:      "/api/v1/products",
+		Summary:   "List products",
+		Tags:      []string{"products"},
+		Module:    "products",
+		Operation: `query ListProducts { products { id name price quantity } }`,
+	},
+	{
+		Method:    http.MethodGet,
+		Path:      "/api/v1/products/{id}",
+		Summary:   "Get a product",
+		Tags:      []string{"products"},
+		Module:    "products",
+		Operation: `query GetProduct($id: Int!) { product(id: $id) { id name price quantity } }`,
+	},
+	{
+		Method:    http.MethodPost,
+		Path:      "/api/v1/products",
+		Summary:   "Add a product",
+		Tags:      []string{"products"},
+		Module:    "products",
+		Operation: `mutation CreateProduct($input: ProductInput!) { createProduct(input: $input) { result { id name price quantity } userErrors { field message } } }`,
+		Body:      "input",
+		Status:    http.StatusCreated,
+		Auth:      true,
+	},
+	{
+		Method:    http.MethodPost,
+		Path:      "/api/v1/users",
+		Summary:   "Sign up",
+		Tags:      []string{"users"},
+		Module:    "users",
+		Operation: `mutation SignUp($input: SignupInput!) { signUp(input: $input) { result { id name email } userErrors { field message } } }`,
+		Body:      "input",
+		Status:    http.StatusCreated,
+	},
+	{
+		Method:    http.MethodPost,
+		Path:      "/api/v1/session",
+		Summary:   "Log in, setting the session cookie",
+		Tags:      []string{"users"},
+		Module:    "users",
+		Operation: `mutation Login($email: String!, $password: String!) { userLogin(email: $email, password: $password) { user { id name email } expiresAt roles } }`,
+	},
+	{
+		Method:    http.MethodDelete,
+		Path:      "/api/v1/session",
+		Summary:   "Log out",
+		Tags:      []string{"users"},
+		Module:    "users",
+		Operation: `mutation Logout { logout }`,
+		Auth:      true,
+	},
+	{
+		Method:    http.MethodGet,
+		Path:      "/api/v1/cart/items",
+		Summary:   "List the items in the cart",
+		Tags:      []string{"cart"},
+		Module:    "cart",
+		Operation: `query ListCartItems { GetAllCartItems { productId quantity total } }`,
+		Auth:      true,
+	},
+	{
+		Method:    http.MethodPost,
+		Path:      "/api/v1/cart/items",
+		Summary:   "Add a product to the cart",
+		Tags:      []string{"cart"},
+		Module:    "cart",
+		Operation: `mutation AddCartItem($productId: Int!, $quantity: Int!) { AddToCart(productId: $productId, quantity: $quantity) { productId quantity total } }`,
+		Status:    http.StatusCreated,
+		Auth:      true,
+	},
+	{
+		Method:    http.MethodDelete,
+		Path:      "/api/v1/cart/items/{productId}",
+		Summary:   "Remove a product from the cart",
+		Tags:      []string{"cart"},
+		Module:    "cart",
+		Operation: `mutation RemoveCartItem($productId: Int!) { RemoveFromCart(productId: $productId) { productId quantity total } }`,
+		Auth:      true,
+	},
+	{
+		Method:    http.MethodGet,
+		Path:      "/api/v1/wishlist/items",
+		Summary:   "List the wishlist",
+		Tags:      []string{"wishlist"},
+		Module:    "wishlist",
+		Operation: `query ListWishlistItems { GetAllWishlist { productId } }`,
+		Auth:      true,
+	},
+	{
+		Method:    http.MethodPost,
+		Path:      "/api/v1/wishlist/items",
+		Summary:   "Add a product to the wishlist",
+		Tags:      []string{"wishlist"},
+		Module:    "wishlist",
+		Operation: `mutation AddWishlistItem($productId: Int!) { AddToWishList(productId: $productId) { productId } }`,
+		Status:    http.StatusCreated,
+		Auth:      true,
+	},
+	{
+		Method:    http.MethodDelete,
+		Path:      "/api/v1/wishlist/items/{productId}",
+		Summary:   "Remove a product from the wishlist",
+		Tags:      []string{"wishlist"},
+		Module:    "wishlist",
+		Operation: `mutation RemoveWishlistItem($productId: Int!) { RemoveFromWishlist(productId: $productId) { productId } }`,
+		Auth:      true,
+	},
+	{
+		Method:    http.MethodGet,
+		Path:      "/api/v1/orders",
+		Summary:   "List your orders",
+		Tags:      []string{"orders"},
+		Module:    "orders",
+		Operation: `query ListOrders { GetAllOrdersUser { orderId addressId orderStatus paymentType total } }`,
+		Auth:      true,
+	},
+	{
+		Method:    http.MethodPost,
+		Path:      "/api/v1/orders",
+		Summary:   "Order everything in the cart; send an Idempotency-Key header to retry safely",
+		Tags:      []string{"orders"},
+		Module:    "orders",
+		Operation: `mutation PlaceOrder { OrderAll { orderId } }`,
+		Status:    http.StatusCreated,
+		Auth:      true,
+	},
+	{
+		Method:    http.MethodGet,
+		Path:      "/api/v1/orders/{orderId}",
+		Summary:   "Get an order",
+		Tags:      []string{"orders"},
+		Module:    "orders",
+		Operation: `query GetOrder($orderId: Int!) { GetOrder(orderId: $orderId) { orderId addressId orderStatus paymentType total } }`,
+		Auth:      true,
+	},
+	{
+		Method:    http.MethodPost,
+		Path:      "/api/v1/orders/{orderId}/cancel",
+		Summary:   "Cancel an order",
+		Tags:      []string{"orders"},
+		Module:    "orders",
+		Operation: `mutation CancelOrder($orderId: Int!) { UserCancelOrder(orderId: $orderId) { orderId orderStatus } }`,
+		Auth:      true,
+	},
+}
+
+// Enabled returns the routes of the modules in the comma separated list,
+// which names schema modules the way SCHEMA_MODULES does, or every route
+// when it is empty.
+func Enabled(routes []Route, modules string) []Route {
+	if strings.TrimSpace(modules) == "" {
+		return routes
+	}
+	enabled := map[string]bool{}
+	for _, name := range strings.Split(modules, ",") {
+		enabled[strings.TrimSpace(name)] = true
+	}
+	var res []Route
+	for _, r := range routes {
+		if enabled[r.Module] {
+			res = append(res, r)
+		}
+	}
+	return res
+}
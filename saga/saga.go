@@ -0,0 +1,260 @@
+// Package saga runs mutations that span several services as a sequence of
+// steps with compensating actions, recording progress under an idempotency
+// key so an interrupted run can be finished instead of repeated.
+package saga
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vishnusunil243/api_gateway/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Step is one action of a multi-service mutation. Do may read and write the
+// record data, which is how later steps see the results of earlier ones.
+// Compensate undoes a completed Do and may be nil when the upstream offers
+// no way to undo it.
+type Step struct {
+	Name       string
+	Do         func(ctx context.Context, data map[string]string) error
+	Compensate func(ctx context.Context, data map[string]string) error
+}
+
+// Saga runs steps in order and records progress under an idempotency key.
+//
+// Running the same key again returns a completed record untouched and
+// continues an incomplete one from the first step that has not finished.
+// When a step fails and every completed step can be compensated, the
+// completed steps are undone in reverse order; otherwise the record is left
+// incomplete so that a retry or Resume can finish it.
+type Saga struct {
+	Name  string
+	Steps []Step
+	Store Store
+	// Retries is how many extra attempts a step gets on transient upstream
+	// errors before the run is given up.
+	Retries int
+	Backoff time.Duration
+
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock serializes the runs of one key, it is dropped once no run holds
+// or waits for it.
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// Error reports a saga run that did not complete.
+type Error struct {
+	Saga      string
+	Step      string
+	Resumable bool
+	Err       error
+}
+
+func (e *Error) Error() string {
+	if e.Resumable {
+		return fmt.Sprintf("%s could not finish step %s and can be retried: %s", e.Saga, e.Step, e.Err.Error())
+	}
+	return fmt.Sprintf("%s failed at step %s: %s", e.Saga, e.Step, e.Err.Error())
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	code := "SAGA_FAILED"
+	if e.Resumable {
+		code = "SAGA_INCOMPLETE"
+	}
+	return map[string]interface{}{
+		"code":      code,
+		"step":      e.Step,
+		"retryable": e.Resumable,
+	}
+}
+
+// Run executes the saga for key. data seeds the record on the first run and
+// is ignored when the key is already known.
+func (s *Saga) Run(ctx context.Context, key string, data map[string]string) (*Record, error) {
+	unlock := s.lock(key)
+	defer unlock()
+	rec, ok, err := s.Store.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || rec.Status == StatusCompensated {
+		rec = &Record{Key: key, Saga: s.Name, Data: data}
+	}
+	if rec.Status == StatusCompleted {
+		return rec, nil
+	}
+	return rec, s.run(ctx, rec)
+}
+
+// Resume retries every incomplete record of the saga once.
+func (s *Saga) Resume(ctx context.Context) {
+	records, err := s.Store.Incomplete(ctx, s.Name)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to list incomplete sagas", "saga", s.Name, "error", err.Error())
+		return
+	}
+	for _, listed := range records {
+		s.resume(ctx, listed.Key)
+	}
+}
+
+// resume runs the record of key unless a retry finished it since it was
+// listed.
+func (s *Saga) resume(ctx context.Context, key string) {
+	unlock := s.lock(key)
+	defer unlock()
+	rec, ok, err := s.Store.Load(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to load saga", "saga", s.Name, "key", key, "error", err.Error())
+		return
+	}
+	if !ok || rec.Status != StatusIncomplete {
+		return
+	}
+	if err := s.run(ctx, rec); err != nil {
+		logging.FromContext(ctx).Warn("saga still incomplete", "saga", s.Name, "key", key, "error", err.Error())
+	}
+}
+
+// ResumeEvery calls Resume on every tick until ctx is done.
+func (s *Saga) ResumeEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Resume(ctx)
+		}
+	}
+}
+
+func (s *Saga) run(ctx context.Context, rec *Record) error {
+	rec.Status = StatusRunning
+	rec.Attempts++
+	if rec.Data == nil {
+		rec.Data = map[string]string{}
+	}
+	for _, step := range s.Steps {
+		if rec.done(step.Name) {
+			continue
+		}
+		if err := s.do(ctx, step, rec.Data); err != nil {
+			return s.fail(ctx, rec, step, err)
+		}
+		rec.Completed = append(rec.Completed, step.Name)
+		rec.UpdatedAt = time.Now()
+		if err := s.Store.Save(ctx, rec); err != nil {
+			return err
+		}
+	}
+	rec.Status = StatusCompleted
+	rec.LastError = ""
+	rec.UpdatedAt = time.Now()
+	return s.Store.Save(ctx, rec)
+}
+
+func (s *Saga) do(ctx context.Context, step Step, data map[string]string) error {
+	var err error
+	for attempt := 0; attempt <= s.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(s.Backoff * time.Duration(attempt)):
+			}
+		}
+		if err = step.Do(ctx, data); err == nil || !transient(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (s *Saga) fail(ctx context.Context, rec *Record, failed Step, err error) error {
+	rec.LastError = err.Error()
+	rec.UpdatedAt = time.Now()
+	if s.compensable(rec) {
+		for i := len(s.Steps) - 1; i >= 0; i-- {
+			step := s.Steps[i]
+			if !rec.done(step.Name) {
+				continue
+			}
+			if cerr := step.Compensate(ctx, rec.Data); cerr != nil {
+				logging.FromContext(ctx).Error("saga compensation failed", "saga", s.Name, "step", step.Name, "error", cerr.Error())
+				rec.Status = StatusIncomplete
+				if serr := s.Store.Save(ctx, rec); serr != nil {
+					return serr
+				}
+				return &Error{Saga: s.Name, Step: failed.Name, Resumable: true, Err: err}
+			}
+		}
+		rec.Completed = nil
+		rec.Data = nil
+		rec.Status = StatusCompensated
+		if serr := s.Store.Save(ctx, rec); serr != nil {
+			return serr
+		}
+		return &Error{Saga: s.Name, Step: failed.Name, Err: err}
+	}
+	rec.Status = StatusIncomplete
+	if serr := s.Store.Save(ctx, rec); serr != nil {
+		return serr
+	}
+	return &Error{Saga: s.Name, Step: failed.Name, Resumable: true, Err: err}
+}
+
+func (s *Saga) compensable(rec *Record) bool {
+	for _, step := range s.Steps {
+		if rec.done(step.Name) && step.Compensate == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Saga) lock(key string) func() {
+	s.mu.Lock()
+	if s.locks == nil {
+		s.locks = map[string]*keyLock{}
+	}
+	l, ok := s.locks[key]
+	if !ok {
+		l = &keyLock{}
+		s.locks[key] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(s.locks, key)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func transient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// counter is a step that counts its runs and fails while err is set.
+type counter struct {
+	runs, compensations int
+	err                 error
+}
+
+func (c *counter) step(name string, compensable bool) Step {
+	step := Step{Name: name, Do: func(context.Context, map[string]string) error {
+		c.runs++
+		return c.err
+	}}
+	if compensable {
+		step.Compensate = func(context.Context, map[string]string) error {
+			c.compensations++
+			return nil
+		}
+	}
+	return step
+}
+
+func TestResume(t *testing.T) {
+	ctx := context.Background()
+	first, second := &counter{}, &counter{err: status.Error(codes.Unavailable, "down")}
+	s := &Saga{Name: "signup", Store: NewMemoryStore(time.Hour), Steps: []Step{first.step("user", false), second.step("cart", false)}}
+
+	_, err := s.Run(ctx, "k", nil)
+	var sagaErr *Error
+	if !errors.As(err, &sagaErr) || !sagaErr.Resumable {
+		t.Fatalf("got %v, want a resumable error", err)
+	}
+	second.err = nil
+	s.Resume(ctx)
+	rec, _, _ := s.Store.Load(ctx, "k")
+	if rec.Status != StatusCompleted {
+		t.Errorf("status %s after resume", rec.Status)
+	}
+	if first.runs != 1 || second.runs != 2 {
+		t.Errorf("steps ran %d and %d times", first.runs, second.runs)
+	}
+	s.Resume(ctx)
+	if second.runs != 2 {
+		t.Errorf("a completed saga was resumed")
+	}
+}
+
+func TestCompensation(t *testing.T) {
+	ctx := context.Background()
+	first, second := &counter{}, &counter{err: status.Error(codes.InvalidArgument, "bad")}
+	s := &Saga{Name: "signup", Store: NewMemoryStore(time.Hour), Steps: []Step{first.step("user", true), second.step("cart", true)}}
+
+	_, err := s.Run(ctx, "k", nil)
+	var sagaErr *Error
+	if !errors.As(err, &sagaErr) || sagaErr.Resumable || sagaErr.Step != "cart" {
+		t.Fatalf("got %v, want a failure at cart", err)
+	}
+	if first.compensations != 1 || second.compensations != 0 {
+		t.Errorf("compensated %d and %d times", first.compensations, second.compensations)
+	}
+	rec, _, _ := s.Store.Load(ctx, "k")
+	if rec.Status != StatusCompensated || len(rec.Completed) != 0 {
+		t.Errorf("record %+v", rec)
+	}
+	// a compensated key starts over
+	second.err = nil
+	if _, err := s.Run(ctx, "k", nil); err != nil {
+		t.Fatal(err)
+	}
+	if first.runs != 2 {
+		t.Errorf("first step ran %d times", first.runs)
+	}
+}
+
+// racingStore lets a retry finish the records between Incomplete listing
+// them and Resume locking them.
+type racingStore struct {
+	*MemoryStore
+	listed func()
+}
+
+func (s *racingStore) Incomplete(ctx context.Context, saga string) ([]*Record, error) {
+	records, err := s.MemoryStore.Incomplete(ctx, saga)
+	s.listed()
+	return records, err
+}
+
+func TestResumeAfterRetry(t *testing.T) {
+	ctx := context.Background()
+	first, second := &counter{}, &counter{err: status.Error(codes.Unavailable, "down")}
+	store := &racingStore{MemoryStore: NewMemoryStore(time.Hour)}
+	s := &Saga{Name: "signup", Store: store, Steps: []Step{first.step("user", false), second.step("cart", false)}}
+	if _, err := s.Run(ctx, "k", nil); err == nil {
+		t.Fatal("first run succeeded")
+	}
+	second.err = nil
+	store.listed = func() {
+		if _, err := s.Run(ctx, "k", nil); err != nil {
+			t.Error(err)
+		}
+	}
+	s.Resume(ctx)
+	if second.runs != 2 {
+		t.Errorf("cart step ran %d times, want 2", second.runs)
+	}
+}
+
+type failingStore struct {
+	*MemoryStore
+}
+
+func (failingStore) Save(context.Context, *Record) error {
+	return errors.New("store down")
+}
+
+func TestCompensationSaveError(t *testing.T) {
+	failing := &counter{err: status.Error(codes.InvalidArgument, "bad")}
+	s := &Saga{Name: "signup", Store: failingStore{NewMemoryStore(time.Hour)}, Steps: []Step{failing.step("user", true)}}
+	_, err := s.Run(context.Background(), "k", nil)
+	if err == nil || err.Error() != "store down" {
+		t.Errorf("got %v, want the save error", err)
+	}
+}
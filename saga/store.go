@@ -0,0 +1,102 @@
+package saga
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusRunning     = "running"
+	StatusIncomplete  = "incomplete"
+	StatusCompleted   = "completed"
+	StatusCompensated = "compensated"
+)
+
+// Record is the persisted progress of one saga execution, identified by its
+// idempotency key.
+type Record struct {
+	Key       string            `json:"key"`
+	Saga      string            `json:"saga"`
+	Status    string            `json:"status"`
+	Completed []string          `json:"completed"`
+	Data      map[string]string `json:"data"`
+	Attempts  int               `json:"attempts"`
+	LastError string            `json:"lastError,omitempty"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+func (r *Record) done(step string) bool {
+	for _, s := range r.Completed {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Store persists saga records. Implementations must be safe for concurrent use.
+type Store interface {
+	Load(ctx context.Context, key string) (*Record, bool, error)
+	Save(ctx context.Context, r *Record) error
+	// Incomplete lists the records of a saga that still have steps to run.
+	Incomplete(ctx context.Context, saga string) ([]*Record, error)
+}
+
+// MemoryStore keeps records in process for ttl after their last update.
+type MemoryStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	records map[string]*Record
+}
+
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{ttl: ttl, records: map[string]*Record{}}
+}
+
+func (s *MemoryStore) Load(_ context.Context, key string) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Since(r.UpdatedAt) > s.ttl {
+		delete(s.records, key)
+		return nil, false, nil
+	}
+	return r.clone(), true, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[r.Key] = r.clone()
+	return nil
+}
+
+func (s *MemoryStore) Incomplete(_ context.Context, saga string) ([]*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []*Record
+	for key, r := range s.records {
+		if time.Since(r.UpdatedAt) > s.ttl {
+			delete(s.records, key)
+			continue
+		}
+		if r.Saga == saga && r.Status == StatusIncomplete {
+			res = append(res, r.clone())
+		}
+	}
+	return res, nil
+}
+
+func (r *Record) clone() *Record {
+	c := *r
+	c.Completed = append([]string(nil), r.Completed...)
+	c.Data = make(map[string]string, len(r.Data))
+	for k, v := range r.Data {
+		c.Data[k] = v
+	}
+	return &c
+}
@@ -0,0 +1,269 @@
+package sdl
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/printer"
+)
+
+// Change is one difference between two schemas. Breaking changes can fail
+// queries that worked against the old schema.
+type Change struct {
+	Breaking bool
+	Path     string
+	Message  string
+}
+
+func (c Change) String() string {
+	kind := "safe"
+	if c.Breaking {
+		kind = "BREAKING"
+	}
+	return fmt.Sprintf("%s %s: %s", kind, c.Path, c.Message)
+}
+
+// Diff parses two SDL documents and reports removed types, fields,
+// arguments and enum values, changed field types and nullability changes.
+// Additions are only reported when they are breaking, i.e. new required
+// arguments and input fields.
+func Diff(oldSDL, newSDL string) ([]Change, error) {
+	oldTypes, err := definitions(oldSDL)
+	if err != nil {
+		return nil, fmt.Errorf("old schema: %s", err.Error())
+	}
+	newTypes, err := definitions(newSDL)
+	if err != nil {
+		return nil, fmt.Errorf("new schema: %s", err.Error())
+	}
+	var changes []Change
+	for _, name := range sortedNames(oldTypes) {
+		oldDef := oldTypes[name]
+		newDef, ok := newTypes[name]
+		if !ok {
+			changes = append(changes, Change{Breaking: true, Path: name, Message: "type removed"})
+			continue
+		}
+		if oldDef.GetKind() != newDef.GetKind() {
+			changes = append(changes, Change{Breaking: true, Path: name, Message: fmt.Sprintf("changed from %s to %s", kindName(oldDef), kindName(newDef))})
+			continue
+		}
+		switch oldDef := oldDef.(type) {
+		case *ast.ObjectDefinition:
+			changes = append(changes, diffFields(name, oldDef.Fields, newDef.(*ast.ObjectDefinition).Fields)...)
+		case *ast.InterfaceDefinition:
+			changes = append(changes, diffFields(name, oldDef.Fields, newDef.(*ast.InterfaceDefinition).Fields)...)
+		case *ast.InputObjectDefinition:
+			changes = append(changes, diffInputs(name, "input field", oldDef.Fields, newDef.(*ast.InputObjectDefinition).Fields)...)
+		case *ast.EnumDefinition:
+			newValues := map[string]bool{}
+			for _, v := range newDef.(*ast.EnumDefinition).Values {
+				newValues[v.Name.Value] = true
+			}
+			for _, v := range oldDef.Values {
+				if !newValues[v.Name.Value] {
+					changes = append(changes, Change{Breaking: true, Path: name + "." + v.Name.Value, Message: "enum value removed"})
+				}
+			}
+		case *ast.UnionDefinition:
+			newMembers := map[string]bool{}
+			for _, t := range newDef.(*ast.UnionDefinition).Types {
+				newMembers[t.Name.Value] = true
+			}
+			for _, t := range oldDef.Types {
+				if !newMembers[t.Name.Value] {
+					changes = append(changes, Change{Breaking: true, Path: name, Message: "union member " + t.Name.Value + " removed"})
+				}
+			}
+		}
+	}
+	return changes, nil
+}
+
+func diffFields(typeName string, oldFields, newFields []*ast.FieldDefinition) []Change {
+	var changes []Change
+	byName := map[string]*ast.FieldDefinition{}
+	for _, f := range newFields {
+		byName[f.Name.Value] = f
+	}
+	for _, oldField := range oldFields {
+		path := typeName + "." + oldField.Name.Value
+		newField, ok := byName[oldField.Name.Value]
+		if !ok {
+			changes = append(changes, Change{Breaking: true, Path: path, Message: "field removed"})
+			continue
+		}
+		if c, changed := typeChange(path, oldField.Type, newField.Type, outputCompatible); changed {
+			changes = append(changes, c)
+		}
+		changes = append(changes, diffInputs(path, "argument", oldField.Arguments, newField.Arguments)...)
+	}
+	return changes
+}
+
+// diffInputs compares arguments or input object fields, where a value the
+// client did not send before must not become required.
+func diffInputs(path, what string, oldValues, newValues []*ast.InputValueDefinition) []Change {
+	var changes []Change
+	oldByName := map[string]*ast.InputValueDefinition{}
+	for _, v := range oldValues {
+		oldByName[v.Name.Value] = v
+	}
+	newByName := map[string]*ast.InputValueDefinition{}
+	for _, v := range newValues {
+		newByName[v.Name.Value] = v
+	}
+	for _, oldValue := range oldValues {
+		valuePath := path + "(" + oldValue.Name.Value + ")"
+		if what == "input field" {
+			valuePath = path + "." + oldValue.Name.Value
+		}
+		newValue, ok := newByName[oldValue.Name.Value]
+		if !ok {
+			changes = append(changes, Change{Breaking: true, Path: valuePath, Message: what + " removed"})
+			continue
+		}
+		if c, changed := typeChange(valuePath, oldValue.Type, newValue.Type, inputCompatible); changed {
+			if c.Breaking && newValue.DefaultValue != nil {
+				c.Breaking = false
+			}
+			changes = append(changes, c)
+		}
+	}
+	for _, newValue := range newValues {
+		if _, ok := oldByName[newValue.Name.Value]; ok {
+			continue
+		}
+		if _, required := newValue.Type.(*ast.NonNull); required && newValue.DefaultValue == nil {
+			valuePath := path + "(" + newValue.Name.Value + ")"
+			if what == "input field" {
+				valuePath = path + "." + newValue.Name.Value
+			}
+			changes = append(changes, Change{Breaking: true, Path: valuePath, Message: "required " + what + " added"})
+		}
+	}
+	return changes
+}
+
+func typeChange(path string, oldType, newType ast.Type, compatible func(oldType, newType ast.Type) bool) (Change, bool) {
+	oldName, newName := printer.Print(oldType).(string), printer.Print(newType).(string)
+	if oldName == newName {
+		return Change{}, false
+	}
+	message := fmt.Sprintf("type changed from %s to %s", oldName, newName)
+	if named(oldType) == named(newType) {
+		message = fmt.Sprintf("nullability changed from %s to %s", oldName, newName)
+	}
+	return Change{Breaking: !compatible(oldType, newType), Path: path, Message: message}, true
+}
+
+// outputCompatible reports whether clients reading a field of oldType can
+// read newType: a field may become non-null but not nullable.
+func outputCompatible(oldType, newType ast.Type) bool {
+	if n, ok := newType.(*ast.NonNull); ok {
+		if o, ok := oldType.(*ast.NonNull); ok {
+			return outputCompatible(o.Type, n.Type)
+		}
+		return outputCompatible(oldType, n.Type)
+	}
+	if _, ok := oldType.(*ast.NonNull); ok {
+		return false
+	}
+	return sameShape(oldType, newType, outputCompatible)
+}
+
+// inputCompatible reports whether values clients sent as oldType are still
+// accepted as newType: an input may become nullable but not non-null.
+func inputCompatible(oldType, newType ast.Type) bool {
+	if o, ok := oldType.(*ast.NonNull); ok {
+		if n, ok := newType.(*ast.NonNull); ok {
+			return inputCompatible(o.Type, n.Type)
+		}
+		return inputCompatible(o.Type, newType)
+	}
+	if _, ok := newType.(*ast.NonNull); ok {
+		return false
+	}
+	return sameShape(oldType, newType, inputCompatible)
+}
+
+func sameShape(oldType, newType ast.Type, compatible func(oldType, newType ast.Type) bool) bool {
+	switch o := oldType.(type) {
+	case *ast.List:
+		n, ok := newType.(*ast.List)
+		return ok && compatible(o.Type, n.Type)
+	case *ast.Named:
+		n, ok := newType.(*ast.Named)
+		return ok && o.Name.Value == n.Name.Value
+	}
+	return false
+}
+
+func named(t ast.Type) string {
+	switch t := t.(type) {
+	case *ast.NonNull:
+		return named(t.Type)
+	case *ast.List:
+		return "[" + named(t.Type) + "]"
+	case *ast.Named:
+		return t.Name.Value
+	}
+	return ""
+}
+
+func definitions(sdl string) (map[string]ast.Node, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: sdl})
+	if err != nil {
+		return nil, err
+	}
+	defs := map[string]ast.Node{}
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.ObjectDefinition:
+			defs[def.Name.Value] = def
+		case *ast.InterfaceDefinition:
+			defs[def.Name.Value] = def
+		case *ast.InputObjectDefinition:
+			defs[def.Name.Value] = def
+		case *ast.EnumDefinition:
+			defs[def.Name.Value] = def
+		case *ast.UnionDefinition:
+			defs[def.Name.Value] = def
+		case *ast.ScalarDefinition:
+			defs[def.Name.Value] = def
+		}
+	}
+	return defs, nil
+}
+
+func kindName(n ast.Node) string {
+	switch n.(type) {
+	case *ast.ObjectDefinition:
+		return "object"
+	case *ast.InterfaceDefinition:
+		return "interface"
+	case *ast.InputObjectDefinition:
+		return "input"
+	case *ast.EnumDefinition:
+		return "enum"
+	case *ast.UnionDefinition:
+		return "union"
+	case *ast.ScalarDefinition:
+		return "scalar"
+	}
+	return n.GetKind()
+}
+
+// Breaking filters the breaking changes.
+func Breaking(changes []Change) []Change {
+	var res []Change
+	for _, c := range changes {
+		if c.Breaking {
+			res = append(res, c)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+	return res
+}
@@ -0,0 +1,133 @@
+package sdl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		old, new string
+		want     []string
+	}{
+		{
+			name: "no change",
+			old:  `type Query { a: Int }`,
+			new:  `type Query { a: Int }`,
+		},
+		{
+			name: "field added",
+			old:  `type Query { a: Int }`,
+			new:  `type Query { a: Int b: String }`,
+		},
+		{
+			name: "field removed",
+			old:  `type Query { a: Int b: String }`,
+			new:  `type Query { a: Int }`,
+			want: []string{"BREAKING Query.b: field removed"},
+		},
+		{
+			name: "type removed",
+			old:  `type Query { a: Int } type Old { a: Int }`,
+			new:  `type Query { a: Int }`,
+			want: []string{"BREAKING Old: type removed"},
+		},
+		{
+			name: "field type changed",
+			old:  `type Query { a: Int }`,
+			new:  `type Query { a: String }`,
+			want: []string{"BREAKING Query.a: type changed from Int to String"},
+		},
+		{
+			name: "output made non-null",
+			old:  `type Query { a: Int }`,
+			new:  `type Query { a: Int! }`,
+			want: []string{"safe Query.a: nullability changed from Int to Int!"},
+		},
+		{
+			name: "output made nullable",
+			old:  `type Query { a: Int! }`,
+			new:  `type Query { a: Int }`,
+			want: []string{"BREAKING Query.a: nullability changed from Int! to Int"},
+		},
+		{
+			name: "list item made nullable",
+			old:  `type Query { a: [Int!] }`,
+			new:  `type Query { a: [Int] }`,
+			want: []string{"BREAKING Query.a: nullability changed from [Int!] to [Int]"},
+		},
+		{
+			name: "argument made non-null",
+			old:  `type Query { a(id: Int): Int }`,
+			new:  `type Query { a(id: Int!): Int }`,
+			want: []string{"BREAKING Query.a(id): nullability changed from Int to Int!"},
+		},
+		{
+			name: "argument made nullable",
+			old:  `type Query { a(id: Int!): Int }`,
+			new:  `type Query { a(id: Int): Int }`,
+			want: []string{"safe Query.a(id): nullability changed from Int! to Int"},
+		},
+		{
+			name: "argument removed",
+			old:  `type Query { a(id: Int): Int }`,
+			new:  `type Query { a: Int }`,
+			want: []string{"BREAKING Query.a(id): argument removed"},
+		},
+		{
+			name: "required argument added",
+			old:  `type Query { a: Int }`,
+			new:  `type Query { a(id: Int!): Int }`,
+			want: []string{"BREAKING Query.a(id): required argument added"},
+		},
+		{
+			name: "required argument with a default added",
+			old:  `type Query { a: Int }`,
+			new:  `type Query { a(id: Int! = 1): Int }`,
+		},
+		{
+			name: "optional argument added",
+			old:  `type Query { a: Int }`,
+			new:  `type Query { a(id: Int): Int }`,
+		},
+		{
+			name: "required input field added",
+			old:  `input In { a: Int } type Query { a(in: In): Int }`,
+			new:  `input In { a: Int b: String! } type Query { a(in: In): Int }`,
+			want: []string{"BREAKING In.b: required input field added"},
+		},
+		{
+			name: "enum value removed",
+			old:  `enum E { A B } type Query { a: E }`,
+			new:  `enum E { A } type Query { a: E }`,
+			want: []string{"BREAKING E.B: enum value removed"},
+		},
+		{
+			name: "kind changed",
+			old:  `type T { a: Int } type Query { a: T }`,
+			new:  `input T { a: Int } type Query { a: Int }`,
+			want: []string{"BREAKING Query.a: type changed from T to Int", "BREAKING T: changed from object to input"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			changes, err := Diff(tc.old, tc.new)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDiffInvalid(t *testing.T) {
+	if _, err := Diff(`type Query { a: Int }`, `type Query {`); err == nil {
+		t.Error("no error for an invalid new schema")
+	}
+}
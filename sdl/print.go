@@ -0,0 +1,223 @@
+// Package sdl prints a graphql-go schema as SDL and compares two SDL
+// documents for changes that break existing clients.
+package sdl
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/vishnusunil243/api_gateway/cachecontrol"
+)
+
+var builtinScalars = map[string]bool{
+	"String":  true,
+	"Int":     true,
+	"Float":   true,
+	"Boolean": true,
+	"ID":      true,
+}
+
+var builtinDirectives = map[string]bool{
+	"include":    true,
+	"skip":       true,
+	"deprecated": true,
+}
+
+// Print renders the schema with types, fields, arguments and enum values in
+// name order so that the output only changes when the schema does.
+func Print(schema *graphql.Schema) string {
+	var blocks []string
+	blocks = append(blocks, printSchemaDefinition(schema))
+	var directives []*graphql.Directive
+	for _, d := range schema.Directives() {
+		if !builtinDirectives[d.Name] {
+			directives = append(directives, d)
+		}
+	}
+	sort.Slice(directives, func(i, j int) bool { return directives[i].Name < directives[j].Name })
+	for _, d := range directives {
+		blocks = append(blocks, printDirective(d))
+	}
+	names := make([]string, 0, len(schema.TypeMap()))
+	for name := range schema.TypeMap() {
+		if strings.HasPrefix(name, "__") || builtinScalars[name] {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		blocks = append(blocks, printType(schema.TypeMap()[name]))
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+func printSchemaDefinition(schema *graphql.Schema) string {
+	var b strings.Builder
+	b.WriteString("schema {\n")
+	if t := schema.QueryType(); t != nil {
+		fmt.Fprintf(&b, "  query: %s\n", t.Name())
+	}
+	if t := schema.MutationType(); t != nil {
+		fmt.Fprintf(&b, "  mutation: %s\n", t.Name())
+	}
+	if t := schema.SubscriptionType(); t != nil {
+		fmt.Fprintf(&b, "  subscription: %s\n", t.Name())
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func printDirective(d *graphql.Directive) string {
+	var b strings.Builder
+	b.WriteString(description(d.Description, ""))
+	b.WriteString("directive @" + d.Name)
+	b.WriteString(printArgs(d.Args))
+	b.WriteString(" on " + strings.Join(d.Locations, " | "))
+	return b.String()
+}
+
+func printType(t graphql.Type) string {
+	var b strings.Builder
+	b.WriteString(description(t.Description(), ""))
+	switch t := t.(type) {
+	case *graphql.Scalar:
+		b.WriteString("scalar " + t.Name())
+	case *graphql.Enum:
+		b.WriteString("enum " + t.Name() + " {\n")
+		values := t.Values()
+		sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+		for _, v := range values {
+			b.WriteString(description(v.Description, "  "))
+			b.WriteString("  " + v.Name + deprecated(v.DeprecationReason) + "\n")
+		}
+		b.WriteString("}")
+	case *graphql.InputObject:
+		b.WriteString("input " + t.Name() + " {\n")
+		fields := t.Fields()
+		for _, name := range sortedNames(fields) {
+			f := fields[name]
+			b.WriteString(description(f.Description(), "  "))
+			b.WriteString("  " + name + ": " + f.Type.String() + defaultValue(f.Type, f.DefaultValue) + "\n")
+		}
+		b.WriteString("}")
+	case *graphql.Object:
+		b.WriteString("type " + t.Name())
+		if len(t.Interfaces()) > 0 {
+			var names []string
+			for _, i := range t.Interfaces() {
+				names = append(names, i.Name())
+			}
+			b.WriteString(" implements " + strings.Join(names, " & "))
+		}
+		b.WriteString(printFields(t, t.Fields()))
+	case *graphql.Interface:
+		b.WriteString("interface " + t.Name())
+		b.WriteString(printFields(t, t.Fields()))
+	case *graphql.Union:
+		var names []string
+		for _, member := range t.Types() {
+			names = append(names, member.Name())
+		}
+		sort.Strings(names)
+		b.WriteString("union " + t.Name() + " = " + strings.Join(names, " | "))
+	}
+	return b.String()
+}
+
+func printFields(t graphql.Type, fields graphql.FieldDefinitionMap) string {
+	var b strings.Builder
+	b.WriteString(" {\n")
+	for _, name := range sortedNames(fields) {
+		f := fields[name]
+		b.WriteString(description(f.Description, "  "))
+		b.WriteString("  " + name + printArgs(f.Args) + ": " + f.Type.String() + cacheControl(t, name) + deprecated(f.DeprecationReason) + "\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func printArgs(args []*graphql.Argument) string {
+	if len(args) == 0 {
+		return ""
+	}
+	sorted := append([]*graphql.Argument(nil), args...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+	var parts []string
+	for _, a := range sorted {
+		parts = append(parts, a.Name()+": "+a.Type.String()+defaultValue(a.Type, a.DefaultValue))
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func defaultValue(t graphql.Input, v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return " = " + literal(t, v)
+}
+
+// literal formats a Go default value as a GraphQL value of type t.
+func literal(t graphql.Input, v interface{}) string {
+	switch w := t.(type) {
+	case *graphql.NonNull:
+		return literal(w.OfType, v)
+	case *graphql.Enum:
+		for _, value := range w.Values() {
+			if value.Value == v {
+				return value.Name
+			}
+		}
+	}
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
+
+func cacheControl(t graphql.Type, fieldName string) string {
+	maxAge, ok := cachecontrol.Hint(t, fieldName)
+	if !ok {
+		return ""
+	}
+	return " @cacheControl(maxAge: " + strconv.Itoa(maxAge) + ")"
+}
+
+func deprecated(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return " @deprecated(reason: " + strconv.Quote(reason) + ")"
+}
+
+func description(text, indent string) string {
+	if text == "" {
+		return ""
+	}
+	text = strings.ReplaceAll(text, `"""`, `\"""`)
+	if !strings.Contains(text, "\n") {
+		return indent + `"""` + text + `"""` + "\n"
+	}
+	var b strings.Builder
+	b.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString(indent + line + "\n")
+	}
+	b.WriteString(indent + `"""` + "\n")
+	return b.String()
+}
+
+func sortedNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vishnusunil243/api_gateway/tlsutil"
+)
+
+// Config controls how the gateway listens for clients.
+type Config struct {
+	// Addr is the main listener, HTTPS when a certificate is configured.
+	Addr string
+	// CertFile and KeyFile are reloaded from disk when they change.
+	CertFile string
+	KeyFile  string
+	// RedirectAddr, when set together with TLS, serves plain HTTP that
+	// redirects every request to the HTTPS listener.
+	RedirectAddr string
+}
+
+// LoadConfig reads LISTEN_ADDR, TLS_CERT_FILE, TLS_KEY_FILE and
+// HTTP_REDIRECT_ADDR from the environment.
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		Addr:         os.Getenv("LISTEN_ADDR"),
+		CertFile:     os.Getenv("TLS_CERT_FILE"),
+		KeyFile:      os.Getenv("TLS_KEY_FILE"),
+		RedirectAddr: os.Getenv("HTTP_REDIRECT_ADDR"),
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = ":8081"
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate rejects a certificate without a key and the other way round,
+// which would otherwise serve plain HTTP.
+func (cfg Config) Validate() error {
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	return nil
+}
+
+func (cfg Config) TLSEnabled() bool {
+	return cfg.CertFile != "" && cfg.KeyFile != ""
+}
+
+// ListenAndServe serves h on cfg.Addr. With TLS enabled the certificate is
+// hot reloaded and HTTP/2 is negotiated through ALPN. It returns the error
+// of whichever listener stops first, the redirect one included.
+func ListenAndServe(cfg Config, h http.Handler) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if !cfg.TLSEnabled() {
+		log.Println("listening for http on", cfg.Addr)
+		return srv.ListenAndServe()
+	}
+	keyPair, err := tlsutil.NewKeyPairReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return err
+	}
+	srv.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: keyPair.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+	if cfg.RedirectAddr == "" {
+		log.Println("listening for https on", cfg.Addr)
+		return srv.ListenAndServeTLS("", "")
+	}
+	// either listener failing stops the gateway
+	errs := make(chan error, 2)
+	go func() {
+		log.Println("redirecting http on", cfg.RedirectAddr, "to https")
+		redirect := &http.Server{
+			Addr:              cfg.RedirectAddr,
+			Handler:           redirectHandler(cfg.Addr),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		errs <- fmt.Errorf("redirect listener: %w", redirect.ListenAndServe())
+	}()
+	go func() {
+		log.Println("listening for https on", cfg.Addr)
+		errs <- srv.ListenAndServeTLS("", "")
+	}()
+	return <-errs
+}
+
+func redirectHandler(tlsAddr string) http.Handler {
+	_, tlsPort, _ := net.SplitHostPort(tlsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		} else {
+			// no port, an IPv6 address is still in brackets
+			host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		}
+		if tlsPort != "" && tlsPort != "443" {
+			host = net.JoinHostPort(host, tlsPort)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
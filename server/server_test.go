@@ -0,0 +1,92 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedirectHandler(t *testing.T) {
+	for _, tc := range []struct {
+		tlsAddr, host, want string
+	}{
+		{":443", "example.com", "https://example.com/a?b=c"},
+		{":443", "example.com:8080", "https://example.com/a?b=c"},
+		{":8443", "example.com:8080", "https://example.com:8443/a?b=c"},
+		{":8443", "[::1]:8080", "https://[::1]:8443/a?b=c"},
+		{":8443", "[::1]", "https://[::1]:8443/a?b=c"},
+		{":443", "[::1]", "https://[::1]/a?b=c"},
+		{":443", "[::1]:8080", "https://[::1]/a?b=c"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/a?b=c", nil)
+		r.Host = tc.host
+		w := httptest.NewRecorder()
+		redirectHandler(tc.tlsAddr).ServeHTTP(w, r)
+		if got := w.Header().Get("Location"); got != tc.want {
+			t.Errorf("%s to %s: redirected to %q, want %q", tc.host, tc.tlsAddr, got, tc.want)
+		}
+	}
+}
+
+// keyPair writes a self-signed certificate and its key.
+func keyPair(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestListenAndServeRedirectError(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+	certFile, keyFile := keyPair(t)
+	cfg := Config{Addr: "127.0.0.1:0", CertFile: certFile, KeyFile: keyFile, RedirectAddr: taken.Addr().String()}
+	errs := make(chan error, 1)
+	go func() { errs <- ListenAndServe(cfg, http.NotFoundHandler()) }()
+	select {
+	case err := <-errs:
+		if err == nil || !strings.Contains(err.Error(), "redirect listener") {
+			t.Errorf("got %v, want the redirect listener error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ListenAndServe kept running without its redirect listener")
+	}
+}
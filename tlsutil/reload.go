@@ -0,0 +1,180 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ReloadInterval is how often the reloaders stat their files for changes.
+// Checks happen lazily on the next handshake, so an idle connection pool
+// never touches the disk.
+var ReloadInterval = 10 * time.Second
+
+type watchedFiles struct {
+	paths     []string
+	modTimes  []time.Time
+	lastCheck time.Time
+}
+
+func newWatchedFiles(paths ...string) *watchedFiles {
+	return &watchedFiles{
+		paths:    paths,
+		modTimes: make([]time.Time, len(paths)),
+	}
+}
+
+// changed reports whether any of the files were modified since the last
+// time it returned true. It only stats the files once per ReloadInterval.
+func (w *watchedFiles) changed(now time.Time) (bool, error) {
+	if !w.lastCheck.IsZero() && now.Sub(w.lastCheck) < ReloadInterval {
+		return false, nil
+	}
+	w.lastCheck = now
+	changed := false
+	for i, path := range w.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, err
+		}
+		if !info.ModTime().Equal(w.modTimes[i]) {
+			w.modTimes[i] = info.ModTime()
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// KeyPairReloader serves a certificate/key pair from disk and picks up
+// replacements without a restart.
+type KeyPairReloader struct {
+	certFile string
+	keyFile  string
+
+	mu    sync.Mutex
+	files *watchedFiles
+	cert  *tls.Certificate
+}
+
+func NewKeyPairReloader(certFile, keyFile string) (*KeyPairReloader, error) {
+	r := &KeyPairReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		files:    newWatchedFiles(certFile, keyFile),
+	}
+	if _, err := r.current(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *KeyPairReloader) current() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed, err := r.files.changed(time.Now())
+	if err != nil {
+		if r.cert != nil {
+			// keep serving the old pair while the files are being replaced
+			return r.cert, nil
+		}
+		return nil, err
+	}
+	if changed || r.cert == nil {
+		cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			if r.cert != nil {
+				return r.cert, nil
+			}
+			return nil, fmt.Errorf("failed to load key pair %s: %w", r.certFile, err)
+		}
+		r.cert = &cert
+	}
+	return r.cert, nil
+}
+
+// GetCertificate can be used as tls.Config.GetCertificate on servers.
+func (r *KeyPairReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current()
+}
+
+// GetClientCertificate can be used as tls.Config.GetClientCertificate on clients.
+func (r *KeyPairReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.current()
+}
+
+// CAReloader keeps a certificate pool in sync with a PEM bundle on disk.
+type CAReloader struct {
+	caFile string
+
+	mu    sync.Mutex
+	files *watchedFiles
+	pool  *x509.CertPool
+}
+
+func NewCAReloader(caFile string) (*CAReloader, error) {
+	r := &CAReloader{
+		caFile: caFile,
+		files:  newWatchedFiles(caFile),
+	}
+	if _, err := r.Pool(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Pool returns the current certificate pool, reloading it if the bundle changed.
+func (r *CAReloader) Pool() (*x509.CertPool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed, err := r.files.changed(time.Now())
+	if err != nil {
+		if r.pool != nil {
+			return r.pool, nil
+		}
+		return nil, err
+	}
+	if changed || r.pool == nil {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			if r.pool != nil {
+				return r.pool, nil
+			}
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			if r.pool != nil {
+				return r.pool, nil
+			}
+			return nil, fmt.Errorf("no certificates found in %s", r.caFile)
+		}
+		r.pool = pool
+	}
+	return r.pool, nil
+}
+
+// VerifyPeer verifies a peer chain against the current pool. serverName is
+// checked against the leaf certificate when it is not empty.
+func (r *CAReloader) VerifyPeer(cs tls.ConnectionState, serverName string, usage x509.ExtKeyUsage) error {
+	if len(cs.PeerCertificates) == 0 {
+		return fmt.Errorf("peer did not present a certificate")
+	}
+	pool, err := r.Pool()
+	if err != nil {
+		return err
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		DNSName:       serverName,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	return err
+}
@@ -0,0 +1,149 @@
+package tracing
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Extension creates spans for the parse, validate and execute phases of a
+// GraphQL request and for every field that has its own resolver. Add it to
+// the schema with Schema.AddExtensions.
+type Extension struct{}
+
+type requestKey struct{}
+
+// request tracks the spans of one operation. graphql-go threads a single
+// context through all resolvers, so parents are looked up by response path
+// rather than taken from the incoming context.
+type request struct {
+	mu      sync.Mutex
+	execute trace.Span
+	fields  map[string]trace.Span
+}
+
+func (Extension) Name() string {
+	return "tracing"
+}
+
+func (Extension) Init(ctx context.Context, p *graphql.Params) context.Context {
+	return context.WithValue(ctx, requestKey{}, &request{fields: map[string]trace.Span{}})
+}
+
+func (Extension) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	_, span := tracer().Start(ctx, "graphql.parse")
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (Extension) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	_, span := tracer().Start(ctx, "graphql.validate")
+	return ctx, func(errs []gqlerrors.FormattedError) {
+		if len(errs) > 0 {
+			span.SetStatus(codes.Error, errs[0].Message)
+		}
+		span.End()
+	}
+}
+
+func (Extension) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	ctx, span := tracer().Start(ctx, "graphql.execute")
+	if req, ok := ctx.Value(requestKey{}).(*request); ok {
+		req.execute = span
+	}
+	return ctx, func(res *graphql.Result) {
+		if res != nil && res.HasErrors() {
+			span.SetStatus(codes.Error, res.Errors[0].Message)
+		}
+		span.End()
+	}
+}
+
+func (Extension) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	req, ok := ctx.Value(requestKey{}).(*request)
+	if !ok || !hasResolver(info) {
+		return ctx, func(interface{}, error) {}
+	}
+	if info.Path.Prev == nil {
+		if def, ok := info.Operation.(*ast.OperationDefinition); ok && req.execute != nil {
+			req.execute.SetAttributes(attribute.String("graphql.operation.type", def.Operation))
+			if def.Name != nil {
+				req.execute.SetAttributes(attribute.String("graphql.operation.name", def.Name.Value))
+			}
+		}
+	}
+	req.mu.Lock()
+	parent := req.execute
+	for prev := info.Path.Prev; prev != nil; prev = prev.Prev {
+		if span, ok := req.fields[pathKey(prev)]; ok {
+			parent = span
+			break
+		}
+	}
+	req.mu.Unlock()
+	if parent != nil {
+		ctx = trace.ContextWithSpan(ctx, parent)
+	}
+	ctx, span := tracer().Start(ctx, "graphql.resolve "+info.ParentType.Name()+"."+info.FieldName,
+		trace.WithAttributes(
+			attribute.String("graphql.field.name", info.FieldName),
+			attribute.String("graphql.field.path", pathKey(info.Path)),
+		),
+	)
+	req.mu.Lock()
+	req.fields[pathKey(info.Path)] = span
+	req.mu.Unlock()
+	return ctx, func(_ interface{}, err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (Extension) HasResult() bool {
+	return false
+}
+
+func (Extension) GetResult(context.Context) interface{} {
+	return nil
+}
+
+// hasResolver skips spans for fields served by the default resolver, which
+// only read a value off their parent.
+func hasResolver(info *graphql.ResolveInfo) bool {
+	object, ok := info.ParentType.(*graphql.Object)
+	if !ok {
+		return false
+	}
+	field, ok := object.Fields()[info.FieldName]
+	return ok && field.Resolve != nil
+}
+
+func pathKey(path *graphql.ResponsePath) string {
+	keys := path.AsArray()
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		switch k := key.(type) {
+		case string:
+			parts[i] = k
+		case int:
+			parts[i] = strconv.Itoa(k)
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/vishnusunil243/api_gateway/clientstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor creates a client span for each call to the named
+// upstream and injects the trace context into the outgoing metadata.
+func UnaryClientInterceptor(upstream string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := startClientSpan(ctx, upstream, method)
+		defer span.End()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		endWithStatus(span, err)
+		return err
+	}
+}
+
+func StreamClientInterceptor(upstream string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := startClientSpan(ctx, upstream, method)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			endWithStatus(span, err)
+			span.End()
+			return nil, err
+		}
+		return clientstream.Watch(stream, func(err error) {
+			endWithStatus(span, err)
+			span.End()
+		}), nil
+	}
+}
+
+func startClientSpan(ctx context.Context, upstream, method string) (context.Context, trace.Span) {
+	service, rpc := splitMethod(method)
+	ctx, span := tracer().Start(ctx, strings.TrimPrefix(method, "/"),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", rpc),
+			attribute.String("peer.service", upstream),
+		),
+	)
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+func endWithStatus(span trace.Span, err error) {
+	s := status.Convert(err)
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", s.Code().String()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, s.Message())
+	}
+}
+
+func splitMethod(method string) (string, string) {
+	method = strings.TrimPrefix(method, "/")
+	if i := strings.LastIndex(method, "/"); i >= 0 {
+		return method[:i], method[i+1:]
+	}
+	return "", method
+}
+
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,148 @@
+package upstream
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/vishnusunil243/api_gateway/tlsutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Config describes how the gateway reaches one backend service.
+type Config struct {
+	Name string
+	Addr string
+	// TLS turns on transport security. With no CAFile the system roots are used.
+	TLS bool
+	// CAFile is a PEM bundle used to verify the server certificate.
+	CAFile string
+	// CertFile and KeyFile enable mutual TLS when both are set.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the SNI and the name checked against the server certificate.
+	ServerName string
+}
+
+// LoadConfig reads the settings of an upstream from the environment using
+// the given prefix, e.g. PRODUCT_SERVICE_ADDR, PRODUCT_SERVICE_TLS,
+// PRODUCT_SERVICE_CA_FILE, PRODUCT_SERVICE_CERT_FILE, PRODUCT_SERVICE_KEY_FILE
+// and PRODUCT_SERVICE_SERVER_NAME.
+func LoadConfig(name, prefix, defaultAddr string) (Config, error) {
+	cfg := Config{
+		Name:       name,
+		Addr:       os.Getenv(prefix + "_ADDR"),
+		CAFile:     os.Getenv(prefix + "_CA_FILE"),
+		CertFile:   os.Getenv(prefix + "_CERT_FILE"),
+		KeyFile:    os.Getenv(prefix + "_KEY_FILE"),
+		ServerName: os.Getenv(prefix + "_SERVER_NAME"),
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = defaultAddr
+	}
+	if v := os.Getenv(prefix + "_TLS"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid value for %s_TLS: %w", prefix, err)
+		}
+		cfg.TLS = enabled
+	}
+	if cfg.CAFile != "" || cfg.CertFile != "" {
+		cfg.TLS = true
+	}
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return Config{}, fmt.Errorf("%s: both %s_CERT_FILE and %s_KEY_FILE are required for mTLS", name, prefix, prefix)
+	}
+	return cfg, nil
+}
+
+// Credentials builds the transport credentials for the upstream. Certificate
+// files are watched and reloaded when they change on disk.
+func Credentials(cfg Config) (credentials.TransportCredentials, error) {
+	if !cfg.TLS {
+		return insecure.NewCredentials(), nil
+	}
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func (cfg Config) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerName,
+	}
+	if cfg.CertFile != "" {
+		keyPair, err := tlsutil.NewKeyPairReloader(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.Name, err)
+		}
+		tlsConfig.GetClientCertificate = keyPair.GetClientCertificate
+	}
+	if cfg.CAFile != "" {
+		ca, err := tlsutil.NewCAReloader(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.Name, err)
+		}
+		serverName := cfg.serverName()
+		if serverName == "" {
+			return nil, fmt.Errorf("%s: no server name to verify the certificate of %s against", cfg.Name, cfg.Addr)
+		}
+		// The pool can change after the config is handed to grpc, so the
+		// chain is verified by hand against whatever the reloader holds.
+		// The name is fixed here, the handshake's may be empty for an IP.
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return ca.VerifyPeer(cs, serverName, x509.ExtKeyUsageServerAuth)
+		}
+	}
+	return tlsConfig, nil
+}
+
+// serverName is the name the server certificate must be valid for,
+// ServerName or else the host of Addr, which may be an IP address.
+func (cfg Config) serverName() string {
+	if cfg.ServerName != "" {
+		return cfg.ServerName
+	}
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return strings.Trim(cfg.Addr, "[]")
+	}
+	return host
+}
+
+// Dial creates a client connection to the upstream with its transport
+// credentials and any additional dial options.
+func Dial(cfg Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	creds, err := Credentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, opts...)
+	conn, err := grpc.Dial(cfg.Addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s at %s: %w", cfg.Name, cfg.Addr, err)
+	}
+	return conn, nil
+}
+
+// String is used when logging the upstream at startup.
+func (cfg Config) String() string {
+	mode := "plaintext"
+	switch {
+	case cfg.CertFile != "":
+		mode = "mtls"
+	case cfg.TLS:
+		mode = "tls"
+	}
+	return strings.Join([]string{cfg.Name, cfg.Addr, mode}, " ")
+}
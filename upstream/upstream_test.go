@@ -0,0 +1,87 @@
+package upstream
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// issue returns a CA file and a server certificate it signed for dnsName
+// and ip.
+func issue(t *testing.T, dnsName string, ip net.IP) (string, *x509.Certificate) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{dnsName},
+		IPAddresses:  []net.IP{ip},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(leafDER)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return caFile, leaf
+}
+
+func TestVerifyServerName(t *testing.T) {
+	caFile, leaf := issue(t, "product", net.ParseIP("10.0.0.1"))
+	for _, tc := range []struct {
+		addr, serverName string
+		ok               bool
+	}{
+		{"product:50051", "", true},
+		{"orders:50051", "", false},
+		{"10.0.0.1:50051", "", true},
+		{"10.0.0.2:50051", "", false},
+		{"10.0.0.2:50051", "product", true},
+		{"10.0.0.1:50051", "orders", false},
+	} {
+		tlsConfig, err := Config{Name: "product", Addr: tc.addr, TLS: true, CAFile: caFile, ServerName: tc.serverName}.tlsConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the handshake leaves the name empty when dialing an IP
+		err = tlsConfig.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}})
+		if ok := err == nil; ok != tc.ok {
+			t.Errorf("%s with server name %q: got %v", tc.addr, tc.serverName, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Required rejects missing values and blank strings.
+func Required() Rule {
+	return func(value interface{}) string {
+		switch v := value.(type) {
+		case nil:
+			return "is required"
+		case string:
+			if strings.TrimSpace(v) == "" {
+				return "is required"
+			}
+		}
+		return ""
+	}
+}
+
+// Length bounds the number of characters of a string.
+func Length(min, max int) Rule {
+	return func(value interface{}) string {
+		s, ok := value.(string)
+		if !ok {
+			return ""
+		}
+		n := utf8.RuneCountInString(s)
+		if n < min || n > max {
+			return fmt.Sprintf("must be between %d and %d characters", min, max)
+		}
+		return ""
+	}
+}
+
+// Range bounds an integer value, inclusive.
+func Range(min, max int) Rule {
+	return func(value interface{}) string {
+		n, ok := value.(int)
+		if !ok {
+			return ""
+		}
+		if n < min || n > max {
+			return fmt.Sprintf("must be between %d and %d", min, max)
+		}
+		return ""
+	}
+}
+
+// Min sets a lower bound on an integer value.
+func Min(min int) Rule {
+	return func(value interface{}) string {
+		n, ok := value.(int)
+		if !ok {
+			return ""
+		}
+		if n < min {
+			return fmt.Sprintf("must be at least %d", min)
+		}
+		return ""
+	}
+}
+
+// Email requires a bare address such as jane@example.com.
+func Email() Rule {
+	return func(value interface{}) string {
+		s, ok := value.(string)
+		if !ok {
+			return ""
+		}
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s || !strings.Contains(s[strings.LastIndex(s, "@"):], ".") {
+			return "must be a valid email address"
+		}
+		return ""
+	}
+}
+
+// Password enforces the password policy: at least 8 characters with a
+// letter and a digit.
+func Password() Rule {
+	return func(value interface{}) string {
+		s, ok := value.(string)
+		if !ok {
+			return ""
+		}
+		var letter, digit bool
+		for _, c := range s {
+			switch {
+			case unicode.IsLetter(c):
+				letter = true
+			case unicode.IsDigit(c):
+				digit = true
+			}
+		}
+		if utf8.RuneCountInString(s) < 8 || utf8.RuneCountInString(s) > 72 || !letter || !digit {
+			return "must be 8 to 72 characters and contain a letter and a digit"
+		}
+		return ""
+	}
+}
+
+// Match requires a string to match re.
+func Match(re *regexp.Regexp, message string) Rule {
+	return func(value interface{}) string {
+		s, ok := value.(string)
+		if !ok {
+			return ""
+		}
+		if !re.MatchString(s) {
+			return message
+		}
+		return ""
+	}
+}
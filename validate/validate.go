@@ -0,0 +1,146 @@
+package validate
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Code is the error code reported for invalid arguments.
+const Code = "BAD_USER_INPUT"
+
+// Rule checks one argument value. It returns a message describing the
+// violation, or "" when the value is fine. Values of omitted nullable
+// arguments are nil; rules other than Required let nil through.
+type Rule func(value interface{}) string
+
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error carries every violation found in one call.
+type Error struct {
+	Violations []Violation
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.Field + ": " + v.Message
+	}
+	return "invalid input: " + strings.Join(parts, "; ")
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":       Code,
+		"violations": e.Violations,
+	}
+}
+
+// Rules maps argument names to the rules their values must satisfy. Keys
+// may be dotted paths into input objects, e.g. "input.email".
+type Rules map[string][]Rule
+
+var (
+	mu          sync.Mutex
+	argRules    = map[*graphql.ArgumentConfig][]Rule{}
+	objectRules = map[*graphql.InputObject]Rules{}
+)
+
+// Arg declares an argument together with the rules its value must satisfy.
+// The rules take effect once the field is passed through Field.
+func Arg(t graphql.Input, rules ...Rule) *graphql.ArgumentConfig {
+	arg := &graphql.ArgumentConfig{Type: t}
+	mu.Lock()
+	argRules[arg] = rules
+	mu.Unlock()
+	return arg
+}
+
+// InputObject creates an input type whose fields are checked against rules
+// wherever the type is used as an argument of a Field.
+func InputObject(config graphql.InputObjectConfig, rules Rules) *graphql.InputObject {
+	object := graphql.NewInputObject(config)
+	mu.Lock()
+	objectRules[object] = rules
+	mu.Unlock()
+	return object
+}
+
+// Field wraps the resolver of f so that the rules of its arguments are
+// checked before it runs. All violations are returned in a single error.
+func Field(f *graphql.Field) *graphql.Field {
+	fieldRules := Rules{}
+	mu.Lock()
+	for name, arg := range f.Args {
+		if r, ok := argRules[arg]; ok && len(r) > 0 {
+			fieldRules[name] = r
+		}
+		object, ok := unwrap(arg.Type).(*graphql.InputObject)
+		if !ok {
+			continue
+		}
+		for field, r := range objectRules[object] {
+			fieldRules[name+"."+field] = r
+		}
+	}
+	mu.Unlock()
+	if len(fieldRules) == 0 {
+		return f
+	}
+	next := f.Resolve
+	f.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+		if err := Check(p.Args, fieldRules); err != nil {
+			return nil, err
+		}
+		return next(p)
+	}
+	return f
+}
+
+// Check runs rules against args and returns an *Error listing every
+// violation, or nil.
+func Check(args map[string]interface{}, rules Rules) error {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var violations []Violation
+	for _, name := range names {
+		value := lookup(args, name)
+		for _, rule := range rules[name] {
+			if msg := rule(value); msg != "" {
+				violations = append(violations, Violation{Field: name, Message: msg})
+				break
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &Error{Violations: violations}
+	}
+	return nil
+}
+
+func lookup(args map[string]interface{}, path string) interface{} {
+	var value interface{} = args
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+func unwrap(t graphql.Input) graphql.Input {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		return nonNull.OfType
+	}
+	return t
+}